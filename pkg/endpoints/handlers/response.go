@@ -19,6 +19,7 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apiserver/pkg/endpoints/handlers/negotiation"
 	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/apiserver/pkg/registry/rest"
 	utiltrace "k8s.io/utils/trace"
 )
 
@@ -78,6 +80,9 @@ func doTransformObject(ctx context.Context, obj runtime.Object, opts interface{}
 		if !ok {
 			return nil, fmt.Errorf("unexpected TableOptions, got %T", opts)
 		}
+		if encoder, ok := newEmbeddedObjectEncoder(scope); ok {
+			ctx = rest.WithEmbeddedObjectEncoder(ctx, encoder)
+		}
 		return asTable(ctx, obj, options, scope, target.GroupVersion())
 
 	default:
@@ -159,6 +164,62 @@ func (e errNotAcceptable) Status() metav1.Status {
 	}
 }
 
+// embeddedObjectEncoder encodes the objects embedded in the rows of tables: objects are converted
+// to the version of the endpoint, like asTable does for the objects it embeds itself, and encoded
+// with the serializer of the endpoint, while their metadata embedded as PartialObjectMetadata is
+// encoded by the meta serializer.
+type embeddedObjectEncoder struct {
+	convertor runtime.ObjectConvertor
+	version   schema.GroupVersion
+	object    runtime.Encoder
+	metadata  runtime.Encoder
+	id        runtime.Identifier
+}
+
+// newEmbeddedObjectEncoder returns the encoder of the objects embedded in the tables of scope.
+// Tables are only served as JSON or YAML, which is also serialized through JSON, and embedded
+// objects are copied verbatim into the JSON of the table, so they are always encoded as JSON. ok
+// is false if the endpoint has no JSON serializer or no convertor, in which case the objects are
+// embedded unencoded, and serialized with the table.
+func newEmbeddedObjectEncoder(scope *RequestScope) (runtime.Encoder, bool) {
+	if scope.Serializer == nil || scope.Convertor == nil {
+		return nil, false
+	}
+	info, ok := runtime.SerializerInfoForMediaType(scope.Serializer.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	if !ok {
+		return nil, false
+	}
+	metaInfo, ok := runtime.SerializerInfoForMediaType(metainternalversionscheme.Codecs.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	if !ok {
+		return nil, false
+	}
+	version := scope.Kind.GroupVersion()
+	return &embeddedObjectEncoder{
+		convertor: scope.Convertor,
+		version:   version,
+		object:    info.Serializer,
+		metadata:  metaInfo.Serializer,
+		id:        runtime.Identifier(fmt.Sprintf("embedded(%s,%s)", info.Serializer.Identifier(), version)),
+	}, true
+}
+
+// Encode implements runtime.Encoder.
+func (e *embeddedObjectEncoder) Encode(obj runtime.Object, w io.Writer) error {
+	if _, ok := obj.(*metav1.PartialObjectMetadata); ok {
+		return e.metadata.Encode(obj, w)
+	}
+	converted, err := e.convertor.ConvertToVersion(obj, e.version)
+	if err != nil {
+		return err
+	}
+	return e.object.Encode(converted, w)
+}
+
+// Identifier implements runtime.Encoder.
+func (e *embeddedObjectEncoder) Identifier() runtime.Identifier {
+	return e.id
+}
+
 func asTable(ctx context.Context, result runtime.Object, opts *metav1.TableOptions, scope *RequestScope, groupVersion schema.GroupVersion) (runtime.Object, error) {
 	switch groupVersion {
 	case metav1beta1.SchemeGroupVersion, metav1.SchemeGroupVersion:
@@ -166,7 +227,7 @@ func asTable(ctx context.Context, result runtime.Object, opts *metav1.TableOptio
 		return nil, newNotAcceptableError(fmt.Sprintf("no Table exists in group version %s", groupVersion))
 	}
 
-	obj, err := scope.TableConvertor.ConvertToTable(ctx, result, opts)
+	obj, err := scope.TableConvertor.ConvertToTable(rest.WithTableVersion(ctx, groupVersion), result, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -175,8 +236,17 @@ func asTable(ctx context.Context, result runtime.Object, opts *metav1.TableOptio
 
 	for i := range table.Rows {
 		item := &table.Rows[i]
+		if item.Object.Object == nil && opts.IncludeObject != metav1.IncludeNone {
+			// the convertor already encoded the embedded object, or omitted it deliberately
+			continue
+		}
 		switch opts.IncludeObject {
 		case metav1.IncludeObject:
+			if partial, ok := item.Object.Object.(*metav1.PartialObjectMetadata); ok {
+				// the convertor only embedded the metadata of the object
+				partial.GetObjectKind().SetGroupVersionKind(groupVersion.WithKind("PartialObjectMetadata"))
+				continue
+			}
 			item.Object.Object, err = scope.Convertor.ConvertToVersion(item.Object.Object, scope.Kind.GroupVersion())
 			if err != nil {
 				return nil, err
@@ -193,6 +263,7 @@ func asTable(ctx context.Context, result runtime.Object, opts *metav1.TableOptio
 			item.Object.Object = partial
 		case metav1.IncludeNone:
 			item.Object.Object = nil
+			item.Object.Raw = nil
 		default:
 			err = errors.NewBadRequest(fmt.Sprintf("unrecognized includeObject value: %q", opts.IncludeObject))
 			return nil, err
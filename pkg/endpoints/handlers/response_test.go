@@ -18,21 +18,24 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
-	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
 	examplev1 "k8s.io/apiserver/pkg/apis/example/v1"
 	"k8s.io/apiserver/pkg/endpoints/handlers/negotiation"
 	"k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	utiltrace "k8s.io/utils/trace"
 )
 
 var _ runtime.CacheableObject = &mockCacheableObject{}
@@ -102,9 +105,10 @@ func TestCacheableObject(t *testing.T) {
 	}
 	podMeta.GetObjectKind().SetGroupVersionKind(pomGVK)
 	podTable := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{Kind: "Table", APIVersion: tableGVK.GroupVersion().String()},
 		Rows: []metav1.TableRow{
 			{
-				Cells: []interface{}{pod.Name, pod.CreationTimestamp.Time.UTC().Format(time.RFC3339)},
+				Cells: []interface{}{pod.Name, ""},
 			},
 		},
 	}
@@ -171,3 +175,90 @@ func TestCacheableObject(t *testing.T) {
 		})
 	}
 }
+
+func TestAsTableEmbeddedMetadata(t *testing.T) {
+	pod := &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "namespace"}}
+	scope := &RequestScope{
+		TableConvertor: rest.NewDefaultTableConvertor(examplev1.Resource("Pod"), rest.WithMaxEmbeddedObjectBytes(1)),
+	}
+
+	result, err := asTable(context.TODO(), pod, &metav1.TableOptions{IncludeObject: metav1.IncludeObject}, scope, metav1beta1.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial, ok := result.(*metav1.Table).Rows[0].Object.Object.(*metav1.PartialObjectMetadata)
+	if !ok {
+		t.Fatalf("expected the metadata of the object to be embedded, got %#v", result.(*metav1.Table).Rows[0].Object)
+	}
+	if partial.Name != "foo" || partial.GroupVersionKind() != metav1beta1.SchemeGroupVersion.WithKind("PartialObjectMetadata") {
+		t.Errorf("unexpected embedded metadata: %#v", partial)
+	}
+}
+
+func TestAsTableOmittedObject(t *testing.T) {
+	pod := &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "namespace"}}
+	scope := &RequestScope{
+		TableConvertor: rest.NewDefaultTableConvertor(examplev1.Resource("Pod"), rest.WithNeverIncludeObject()),
+	}
+
+	for _, policy := range []metav1.IncludeObjectPolicy{"", metav1.IncludeMetadata, metav1.IncludeObject} {
+		result, err := asTable(context.TODO(), pod, &metav1.TableOptions{IncludeObject: policy}, scope, metav1.SchemeGroupVersion)
+		if err != nil {
+			t.Fatalf("%q: %v", policy, err)
+		}
+		if object := result.(*metav1.Table).Rows[0].Object; object.Object != nil || object.Raw != nil {
+			t.Errorf("%q: expected no embedded object, got %#v", policy, object)
+		}
+	}
+}
+
+func TestTransformResponseObjectTableEmbeddedObjects(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "namespace", Labels: map[string]string{"b": "2", "a": "1"}}, Spec: example.PodSpec{NodeName: "node"}}
+	scope := &RequestScope{
+		Namer:            &mockNamer{},
+		Serializer:       codecs,
+		Convertor:        scheme,
+		Kind:             examplev1.SchemeGroupVersion.WithKind("Pod"),
+		MetaGroupVersion: metav1.SchemeGroupVersion,
+		TableConvertor:   rest.NewDefaultTableConvertor(examplev1.Resource("Pod"), rest.WithCanonicalEmbeddedObjects()),
+	}
+
+	for _, tc := range []struct {
+		includeObject string
+		expected      string
+	}{
+		{
+			includeObject: "Object",
+			expected:      `{"apiVersion":"example.apiserver.k8s.io/v1","kind":"Pod","metadata":{"creationTimestamp":null,"labels":{"a":"1","b":"2"},"name":"foo","namespace":"namespace"},"spec":{"nodeName":"node"},"status":{}}`,
+		},
+		{
+			includeObject: "Metadata",
+			expected:      `{"apiVersion":"meta.k8s.io/v1","kind":"PartialObjectMetadata","metadata":{"creationTimestamp":null,"labels":{"a":"1","b":"2"},"name":"foo","namespace":"namespace"}}`,
+		},
+	} {
+		t.Run(tc.includeObject, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/apis/example.apiserver.k8s.io/v1/namespaces/namespace/pods/foo?includeObject="+tc.includeObject, nil)
+			req.Header.Set("Accept", "application/json;as=Table;v=v1;g=meta.k8s.io")
+			mediaType, _, err := negotiation.NegotiateOutputMediaType(req, scope.Serializer, scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			transformResponseObject(request.WithRequestInfo(context.TODO(), &request.RequestInfo{}), scope, utiltrace.New("test"), req, w, http.StatusOK, mediaType, pod)
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+			}
+			var table struct {
+				Rows []struct {
+					Object json.RawMessage `json:"object"`
+				} `json:"rows"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &table); err != nil {
+				t.Fatal(err)
+			}
+			if len(table.Rows) != 1 || string(table.Rows[0].Object) != tc.expected {
+				t.Errorf("expected the embedded object %s, got %s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
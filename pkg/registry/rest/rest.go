@@ -139,10 +139,42 @@ type GetterWithOptions interface {
 	NewGetOptions() (runtime.Object, bool, string)
 }
 
+// TableConvertor converts objects and lists to tables for clients requesting the Table media type.
+// A TableConvertor is shared by all the requests for a resource, so implementations must be safe
+// for concurrent use. The returned table belongs to the caller, which may modify its rows; it must
+// not share mutable state with other conversions.
 type TableConvertor interface {
 	ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error)
 }
 
+// TableChunkConvertor is a TableConvertor that can convert objects as successive chunks of a
+// stream, such as watch events, where column definitions are only sent with the first chunk.
+type TableChunkConvertor interface {
+	TableConvertor
+	// ConvertToTableChunk behaves like ConvertToTable, but omits the column definitions unless
+	// isFirst is true.
+	ConvertToTableChunk(ctx context.Context, object runtime.Object, tableOptions runtime.Object, isFirst bool) (*metav1.Table, error)
+}
+
+// TableStreamConvertor is a TableConvertor that can hand out rows one at a time instead of
+// accumulating them, which bounds memory use when converting very large lists.
+type TableStreamConvertor interface {
+	TableConvertor
+	// ConvertToTableStream converts object and passes each row to emit in order. Conversion stops
+	// at the first error returned by emit, which is returned. On success the returned table carries
+	// the list metadata and column definitions but no rows.
+	ConvertToTableStream(ctx context.Context, object runtime.Object, tableOptions runtime.Object, emit func(metav1.TableRow) error) (*metav1.Table, error)
+}
+
+// TableColumnsConvertor is a TableConvertor that can render columns chosen for each conversion,
+// for instance by interactive tools letting users pick columns.
+type TableColumnsConvertor interface {
+	TableConvertor
+	// ConvertToTableWithColumns behaves like ConvertToTable, but renders the given columns instead
+	// of the columns of the convertor.
+	ConvertToTableWithColumns(ctx context.Context, object runtime.Object, tableOptions runtime.Object, columns []PrinterColumn) (*metav1.Table, error)
+}
+
 // GracefulDeleter knows how to pass deletion options to allow delayed deletion of a
 // RESTful object.
 type GracefulDeleter interface {
@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// AssertTableConvertor converts sample, an object or a list served by a resource, with c and fails
+// t if the resulting tables break the invariants expected by clients: a table is returned, every
+// row has one cell per column and its cells can be serialized, column definitions are omitted when
+// NoHeaders is requested, and no object is embedded when the includeObject policy is None.
+func AssertTableConvertor(t *testing.T, c rest.TableConvertor, sample runtime.Object) {
+	t.Helper()
+	ctx := context.TODO()
+
+	table, err := c.ConvertToTable(ctx, sample.DeepCopyObject(), &metav1.TableOptions{})
+	if err != nil {
+		t.Fatalf("unable to convert %T to a table: %v", sample, err)
+	}
+	if table == nil {
+		t.Fatalf("converting %T returned a nil table", sample)
+	}
+	if len(table.ColumnDefinitions) == 0 {
+		t.Errorf("converting %T returned no column definitions", sample)
+	}
+	if err := rest.ValidateTable(table); err != nil {
+		t.Errorf("converting %T returned an invalid table: %v", sample, err)
+	}
+	columns := len(table.ColumnDefinitions)
+	rows := len(table.Rows)
+
+	table, err = c.ConvertToTable(ctx, sample.DeepCopyObject(), &metav1.TableOptions{NoHeaders: true, IncludeObject: metav1.IncludeNone})
+	if err != nil {
+		t.Fatalf("unable to convert %T to a table without headers: %v", sample, err)
+	}
+	if table == nil {
+		t.Fatalf("converting %T without headers returned a nil table", sample)
+	}
+	if table.ColumnDefinitions != nil {
+		t.Errorf("converting %T with NoHeaders returned column definitions: %v", sample, table.ColumnDefinitions)
+	}
+	if len(table.Rows) != rows {
+		t.Errorf("converting %T returned %d rows with headers and %d rows without", sample, rows, len(table.Rows))
+	}
+	for i, row := range table.Rows {
+		if len(row.Cells) != columns {
+			t.Errorf("row %d of %T has %d cells without headers, but the table has %d columns", i, sample, len(row.Cells), columns)
+		}
+		if row.Object.Object != nil || row.Object.Raw != nil {
+			t.Errorf("row %d of %T embeds an object although the includeObject policy is None", i, sample)
+		}
+	}
+}
+
+// CheckTableInvariants fails t if table breaks the invariants clients like kubectl rely on: the
+// table is not nil, every column definition has a name and a type, every row has one cell per
+// column when column definitions are present, and every embedded object, decoded or raw, carries
+// its apiVersion and kind. Objects embedded with the Object policy only carry their kind if the
+// convertor sets it, see rest.WithScheme and rest.WithObjectConvertor.
+func CheckTableInvariants(t testing.TB, table *metav1.Table) {
+	t.Helper()
+	if table == nil {
+		t.Fatalf("the table is nil")
+	}
+	for i, def := range table.ColumnDefinitions {
+		if len(def.Name) == 0 || len(def.Type) == 0 {
+			t.Errorf("column %d has no name or no type: %+v", i, def)
+		}
+	}
+	columns := len(table.ColumnDefinitions)
+	for i, row := range table.Rows {
+		if columns > 0 && len(row.Cells) != columns {
+			t.Errorf("row %d has %d cells, but the table has %d columns", i, len(row.Cells), columns)
+		}
+		switch {
+		case row.Object.Object != nil:
+			if gvk := row.Object.Object.GetObjectKind().GroupVersionKind(); len(gvk.Version) == 0 || len(gvk.Kind) == 0 {
+				t.Errorf("row %d embeds a %T without apiVersion or kind", i, row.Object.Object)
+			}
+		case len(row.Object.Raw) > 0:
+			var typeMeta metav1.TypeMeta
+			if err := json.Unmarshal(row.Object.Raw, &typeMeta); err != nil {
+				t.Errorf("row %d embeds an object that cannot be decoded: %v", i, err)
+			} else if len(typeMeta.APIVersion) == 0 || len(typeMeta.Kind) == 0 {
+				t.Errorf("row %d embeds an object without apiVersion or kind", i)
+			}
+		}
+	}
+}
+
+// DiffTables compares the column definitions and the rows of want and got, cell by cell, and
+// returns a description of every difference, one per line, or an empty string if they match. It
+// is meant to compare converted tables with golden fixtures, for example:
+//
+//	if diff := resttest.DiffTables(expected, table); len(diff) > 0 {
+//		t.Errorf("unexpected table:\n%s", diff)
+//	}
+//
+// The list metadata of the tables and the objects embedded in their rows are not compared.
+func DiffTables(want, got *metav1.Table) string {
+	switch {
+	case want == nil && got == nil:
+		return ""
+	case want == nil:
+		return "want no table, got a table\n"
+	case got == nil:
+		return "want a table, got no table\n"
+	}
+	var diff strings.Builder
+	if len(want.ColumnDefinitions) != len(got.ColumnDefinitions) {
+		fmt.Fprintf(&diff, "columns: want %d, got %d\n", len(want.ColumnDefinitions), len(got.ColumnDefinitions))
+	}
+	for i := 0; i < len(want.ColumnDefinitions) || i < len(got.ColumnDefinitions); i++ {
+		switch {
+		case i >= len(got.ColumnDefinitions):
+			fmt.Fprintf(&diff, "column %d: want %+v, missing\n", i, want.ColumnDefinitions[i])
+		case i >= len(want.ColumnDefinitions):
+			fmt.Fprintf(&diff, "column %d: unexpected %+v\n", i, got.ColumnDefinitions[i])
+		case want.ColumnDefinitions[i] != got.ColumnDefinitions[i]:
+			fmt.Fprintf(&diff, "column %d: want %+v, got %+v\n", i, want.ColumnDefinitions[i], got.ColumnDefinitions[i])
+		}
+	}
+	if len(want.Rows) != len(got.Rows) {
+		fmt.Fprintf(&diff, "rows: want %d, got %d\n", len(want.Rows), len(got.Rows))
+	}
+	for i := 0; i < len(want.Rows) && i < len(got.Rows); i++ {
+		diffRows(&diff, i, want.Rows[i], got.Rows[i], want.ColumnDefinitions)
+	}
+	return diff.String()
+}
+
+// diffRows writes the differences between the cells and the conditions of the rows at index i of
+// two tables to diff.
+func diffRows(diff *strings.Builder, i int, want, got metav1.TableRow, columns []metav1.TableColumnDefinition) {
+	for j := 0; j < len(want.Cells) || j < len(got.Cells); j++ {
+		cell := fmt.Sprintf("row %d, cell %d", i, j)
+		if j < len(columns) {
+			cell = fmt.Sprintf("row %d, cell %d (%s)", i, j, columns[j].Name)
+		}
+		switch {
+		case j >= len(got.Cells):
+			fmt.Fprintf(diff, "%s: want %s, missing\n", cell, formatCell(want.Cells[j]))
+		case j >= len(want.Cells):
+			fmt.Fprintf(diff, "%s: unexpected %s\n", cell, formatCell(got.Cells[j]))
+		case !reflect.DeepEqual(want.Cells[j], got.Cells[j]):
+			fmt.Fprintf(diff, "%s: want %s, got %s\n", cell, formatCell(want.Cells[j]), formatCell(got.Cells[j]))
+		}
+	}
+	if !reflect.DeepEqual(want.Conditions, got.Conditions) {
+		fmt.Fprintf(diff, "row %d, conditions: want %+v, got %+v\n", i, want.Conditions, got.Conditions)
+	}
+}
+
+// formatCell returns the value of a cell with its type, so that cells that only differ by their
+// type, such as int32(1) and int64(1), are told apart.
+func formatCell(cell interface{}) string {
+	if cell == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%#v (%T)", cell, cell)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resttest
+
+import (
+	"context"
+	"fmt"
+	goruntime "runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+func TestAssertTableConvertor(t *testing.T) {
+	c := rest.NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, rest.WithAgeColumn())
+	AssertTableConvertor(t, c, &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}})
+	AssertTableConvertor(t, c, &example.PodList{Items: []example.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "bar"}},
+	}})
+}
+
+func TestDiffTables(t *testing.T) {
+	want := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name", Type: "string", Format: "name"}, {Name: "Replicas", Type: "integer"}},
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"foo", int64(1)}},
+			{Cells: []interface{}{"bar", int64(2)}},
+		},
+	}
+	if diff := DiffTables(want, want.DeepCopy()); len(diff) > 0 {
+		t.Errorf("expected no difference, got:\n%s", diff)
+	}
+	if diff := DiffTables(nil, nil); len(diff) > 0 {
+		t.Errorf("expected no difference, got:\n%s", diff)
+	}
+	if diff := DiffTables(want, nil); diff != "want a table, got no table\n" {
+		t.Errorf("unexpected difference:\n%s", diff)
+	}
+
+	got := want.DeepCopy()
+	got.ColumnDefinitions[1].Type = "number"
+	got.ColumnDefinitions = append(got.ColumnDefinitions, metav1.TableColumnDefinition{Name: "Age", Type: "string"})
+	got.Rows[0].Cells[1] = int32(1)
+	got.Rows[1].Cells = got.Rows[1].Cells[:1]
+	got.Rows[1].Conditions = []metav1.TableRowCondition{{Type: metav1.RowCompleted, Status: metav1.ConditionTrue}}
+	got.Rows = append(got.Rows, metav1.TableRow{Cells: []interface{}{"baz", nil}})
+
+	expected := strings.Join([]string{
+		"columns: want 2, got 3",
+		"column 1: want {Name:Replicas Type:integer Format: Description: Priority:0}, got {Name:Replicas Type:number Format: Description: Priority:0}",
+		"column 2: unexpected {Name:Age Type:string Format: Description: Priority:0}",
+		"rows: want 2, got 3",
+		"row 0, cell 1 (Replicas): want 1 (int64), got 1 (int32)",
+		"row 1, cell 1 (Replicas): want 2 (int64), missing",
+		"row 1, conditions: want [], got [{Type:Completed Status:True Reason: Message:}]",
+		"",
+	}, "\n")
+	if diff := DiffTables(want, got); diff != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, diff)
+	}
+}
+
+// recordingTB records the failures of a test instead of failing it.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (t *recordingTB) Helper() {}
+
+func (t *recordingTB) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingTB) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+	goruntime.Goexit()
+}
+
+// checkTableInvariants returns the failures of CheckTableInvariants for table.
+func checkTableInvariants(table *metav1.Table) []string {
+	t := &recordingTB{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		CheckTableInvariants(t, table)
+	}()
+	wg.Wait()
+	return t.failures
+}
+
+func TestCheckTableInvariants(t *testing.T) {
+	c := rest.NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+	table, err := c.ConvertToTable(context.TODO(), &example.PodList{Items: []example.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	CheckTableInvariants(t, table)
+
+	if failures := checkTableInvariants(nil); len(failures) != 1 {
+		t.Errorf("expected a nil table to fail, got %q", failures)
+	}
+	broken := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name", Type: "string"}, {Name: "Ready"}},
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"foo"}},
+			{Cells: []interface{}{"bar", "1/1"}, Object: runtime.RawExtension{Object: &example.Pod{}}},
+			{Cells: []interface{}{"baz", "0/1"}, Object: runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"baz"}}`)}},
+			{Cells: []interface{}{"qux", "0/1"}, Object: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Pod"}`)}},
+		},
+	}
+	expected := []string{
+		"column 1 has no name or no type: {Name:Ready Type: Format: Description: Priority:0}",
+		"row 0 has 1 cells, but the table has 2 columns",
+		"row 1 embeds a *example.Pod without apiVersion or kind",
+		"row 2 embeds an object without apiVersion or kind",
+	}
+	if failures := checkTableInvariants(broken); strings.Join(failures, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected failures:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(failures, "\n"))
+	}
+}
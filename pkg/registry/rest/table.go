@@ -18,81 +18,1339 @@ package rest
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/warning"
+	"k8s.io/klog/v2"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	"k8s.io/utils/clock"
 )
 
 type defaultTableConvertor struct {
 	defaultQualifiedResource schema.GroupResource
+	// columns are the columns rendered for every row, in order.
+	columns []column
+	// definitions are the precomputed definitions of columns. They must be copied before being
+	// returned to callers.
+	definitions []metav1.TableColumnDefinition
+	// conditionalColumns is true if any of columns is only rendered for some requests.
+	conditionalColumns bool
+	// clock is the source of the current time for all time-derived cells.
+	clock clock.Clock
+	// showAge replaces the "Created At" column with a relative "Age" column.
+	showAge bool
+	// ageFormat renders the cells of the Age column, AgeLong if unset.
+	ageFormat AgeFormat
+	// timestampLayout formats the cells of timestamp columns, time.RFC3339 if unset.
+	timestampLayout string
+	// showNamespace prepends a "Namespace" column.
+	showNamespace bool
+	// validate checks every converted table with ValidateTable.
+	validate bool
+	// rowConditions computes the conditions of each row.
+	rowConditions RowConditionsFunc
+	// redactions hide the cells of some columns from some requests.
+	redactions []redaction
+	// terminalPhases are the phases of the objects whose rows get a completed condition.
+	terminalPhases sets.String
+	// warningTypes are the types of the objects whose rows get a warning condition, read by
+	// typeCell.
+	warningTypes sets.String
+	typeCell     func(context.Context, runtime.Object, metav1.Object) (interface{}, error)
+	// nameFunc computes the cell of the name column, instead of the object name.
+	nameFunc func(runtime.Object) string
+	// extraColumns are appended after the columns of the convertor.
+	extraColumns []column
+	// columnsBefore and columnsAfter are placed around the columns of the convertor, ahead of the
+	// extra columns.
+	columnsBefore []column
+	columnsAfter  []column
+	// failOnExtraColumnError fails the conversion when an extra column cannot be computed, instead
+	// of rendering an empty cell.
+	failOnExtraColumnError bool
+	// bestEffort renders the cells that cannot be computed as empty instead of failing.
+	bestEffort bool
+	// skipInaccessible skips the objects without object metadata instead of failing.
+	skipInaccessible bool
+	// rowFilter skips the objects for which it returns false.
+	rowFilter func(runtime.Object) bool
+	// defaultColumnsWarning warns about conversions with only the default columns, which
+	// defaultColumnsOnly is set for.
+	defaultColumnsWarning bool
+	defaultColumnsOnly    bool
+	// compositeName is the set of columns that together identify an object.
+	compositeName map[string]bool
+	// openAPISchema describes the objects, for the descriptions of the columns that have none.
+	openAPISchema proto.Schema
+	// includeColumns are the names of the only columns rendered, if set.
+	includeColumns []string
+	// columnDescriptions override the descriptions of the columns with the same name.
+	columnDescriptions map[string]string
+	// objectConvertor converts objects embedded with the Object policy to objectVersion.
+	objectConvertor runtime.ObjectConvertor
+	objectVersion   runtime.GroupVersioner
+	// scheme converts internal objects embedded with the Object policy to their preferred
+	// version, if set and objectConvertor is not.
+	scheme *runtime.Scheme
+	// kindColumn prepends a Kind column.
+	kindColumn bool
+	// pooledRows takes the rows of converted tables from a pool, see ReleaseTable.
+	pooledRows bool
+	// neverIncludeObject embeds no object in rows, whatever the requested policy.
+	neverIncludeObject bool
+	// maxEmbeddedObjectBytes is the size above which embedded objects are replaced by their
+	// metadata, unlimited if zero.
+	maxEmbeddedObjectBytes int
+	// canonicalEmbeddedObjects rewrites the JSON encoding of embedded objects in canonical form.
+	canonicalEmbeddedObjects bool
+	// sortColumn is the name of the column whose cells order the rows of converted tables.
+	sortColumn string
+	// sortAscending orders rows by ascending cells of sortColumn instead of descending ones.
+	sortAscending bool
+	// strictListMetadata rejects lists whose list metadata cannot be read.
+	strictListMetadata bool
+	// strictCells rejects cells that are not scalars or simple slices and maps.
+	strictCells bool
+	// sanitizeStrings escapes the non-printable characters of string cells.
+	sanitizeStrings bool
+	// statusRows renders *metav1.Status objects as a single informational row.
+	statusRows bool
+	// listMeta extracts the list metadata of lists, instead of meta.ListAccessor.
+	listMeta func(runtime.Object) (metav1.ListInterface, error)
+	// versionedPrinterColumns are the columns rendered for the requests of some group versions,
+	// and versioned the convertors rendering them.
+	versionedPrinterColumns map[schema.GroupVersion][]PrinterColumn
+	versioned               map[schema.GroupVersion]*defaultTableConvertor
+	// columnsErr fails every conversion, if the columns could not be set.
+	columnsErr error
+	// metrics observes every successful conversion, if set.
+	metrics MetricsRecorder
+	// tableVersion is the group version of the tables of requests without a negotiated version,
+	// meta.k8s.io/v1 if empty.
+	tableVersion schema.GroupVersion
+	// dropEmptyColumns removes the columns whose cells are all empty from converted tables.
+	dropEmptyColumns bool
+	// maxRows is the number of rows above which conversions are truncated, unlimited if zero.
+	maxRows int
+}
+
+// column pairs a column definition with the function that computes its cell for an object.
+type column struct {
+	definition metav1.TableColumnDefinition
+	cell       func(ctx context.Context, obj runtime.Object, m metav1.Object) (interface{}, error)
+	// include reports whether the column is rendered for the request in ctx. A nil include
+	// renders the column for every request.
+	include func(ctx context.Context) bool
+	// jsonPath is the JSONPath the cells are extracted from, if known.
+	jsonPath string
+	// timestamp is set for the columns rendering timestamps as dates, or as ages if the convertor
+	// shows ages.
+	timestamp bool
+	// warningType is set for the column of WithTypeColumn, whose cells also decide the warning
+	// conditions of the rows.
+	warningType bool
+	// redacted is set for the columns whose cells are redacted for the request.
+	redacted bool
+}
+
+// RowConditionsFunc returns the conditions of the table row of obj, for example a
+// metav1.RowCompleted condition for objects that reached a terminal state, so that clients can
+// de-emphasize the row.
+type RowConditionsFunc func(obj runtime.Object) []metav1.TableRowCondition
+
+// TableConvertorOption customizes the convertor returned by NewDefaultTableConvertor.
+type TableConvertorOption func(*defaultTableConvertor)
+
+// WithAgeColumn replaces the "Created At" column with an "Age" column that renders the time
+// elapsed since the object was created, e.g. "5m" or "2y45d".
+func WithAgeColumn() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.showAge = true
+	}
+}
+
+// AgeFormat renders the age of an object, the time elapsed since its creation, in an Age column.
+type AgeFormat func(time.Duration) string
+
+var (
+	// AgeShort renders an age with a single unit, e.g. "45s", "3d" or "2y".
+	AgeShort AgeFormat = duration.ShortHumanDuration
+	// AgeLong renders an age with up to two units, e.g. "45s", "3d4h" or "2y15d". It is the
+	// format of the Age column unless another one is selected.
+	AgeLong AgeFormat = duration.HumanDuration
+	// AgeExact renders an age to the second, e.g. "45s" or "76h3m4s".
+	AgeExact AgeFormat = func(d time.Duration) string {
+		return d.Truncate(time.Second).String()
+	}
+)
+
+// WithAgeFormat replaces the "Created At" column with an "Age" column, like WithAgeColumn, and
+// renders its cells with format.
+func WithAgeFormat(format AgeFormat) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.showAge = true
+		c.ageFormat = format
+	}
+}
+
+// WithTimestampPrecision formats the cells of the "Created At" column, and of the columns of
+// WithTimestampColumn, in UTC with layout instead of time.RFC3339, for instance time.RFC3339Nano
+// to order events created within the same second. Clients parse the cells of "date" columns as
+// RFC3339 timestamps, so custom layouts should only add precision. It has no effect on Age
+// columns.
+func WithTimestampPrecision(layout string) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.timestampLayout = layout
+	}
+}
+
+// WithNamespaceColumn prepends a "Namespace" column, which disambiguates rows when a namespaced
+// resource is listed across all namespaces. The column is omitted when the request in context is
+// scoped to a single namespace, and renders an empty cell for cluster-scoped objects.
+func WithNamespaceColumn() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.showNamespace = true
+	}
+}
+
+// WithTableValidation checks the tables returned by ConvertToTable with ValidateTable and fails
+// the conversion if they are malformed. It is meant for debugging convertors, since validating
+// every cell is expensive.
+func WithTableValidation() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.validate = true
+	}
+}
+
+// WithObjectConvertor converts the objects embedded in rows with the Object includeObject policy
+// to the target version with convertor, so that rows carry the serialization of the object at
+// the version of the request instead of its in-memory, possibly internal, version. Without this
+// option the in-memory object is embedded as is.
+func WithObjectConvertor(convertor runtime.ObjectConvertor, target runtime.GroupVersioner) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.objectConvertor = convertor
+		c.objectVersion = target
+	}
+}
+
+// WithScheme converts the objects embedded in rows with the Object includeObject policy whose kind
+// in scheme has the internal version to the preferred version of their group in scheme, so that
+// clients decode them with an external apiVersion instead of failing on the internal one, and
+// sets the kind of scheme on the copy embedded for external objects without type metadata.
+// Objects unknown to scheme are embedded as is, as without this option. WithObjectConvertor takes
+// precedence over this option.
+func WithScheme(scheme *runtime.Scheme) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.scheme = scheme
+	}
+}
+
+// RowConversionFailed is the type of the condition of the rows that WithBestEffort rendered
+// despite errors. Its message describes the errors.
+const RowConversionFailed metav1.RowConditionType = "ConversionFailed"
+
+// WithBestEffort renders the cells that cannot be computed, for instance for a malformed object,
+// as empty (nil) instead of failing the conversion. The rows of such objects get a
+// RowConversionFailed condition describing the errors, which RowErrors aggregates. Errors that
+// affect the whole conversion, such as invalid table options, still fail it.
+func WithBestEffort() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.bestEffort = true
+	}
+}
+
+// WithSkipInaccessible skips the items of lists that have no object metadata, for instance in
+// heterogeneous aggregated lists, instead of failing the conversion with a NotAcceptable error, so
+// that the other items still render. The number of skipped items is reported to the client in a
+// warning. A single object without metadata still fails the conversion.
+func WithSkipInaccessible() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.skipInaccessible = true
+	}
+}
+
+// WithRowFilter skips the objects for which filter returns false, for instance to hide system
+// objects, in addition to the label and field selectors applied by storage. Filtering applies to
+// each conversion: the pages of a paginated list may contain fewer rows than the requested limit,
+// or none at all while a continue token is returned, and the remaining item count is not set since
+// it counts unfiltered objects.
+func WithRowFilter(filter func(runtime.Object) bool) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.rowFilter = filter
+	}
+}
+
+// WithMaxRows truncates converted tables to their first n rows, as a safety valve against
+// unbounded responses; conversions stop iterating over lists once n rows have been produced. The
+// table of a truncated list has its remaining item count increased by the number of objects that
+// were not converted, unless rows are filtered, and a warning is added to the response through the
+// warning recorder of the request context. Values of n lower than one disable the limit.
+//
+// The limit applies to each conversion and does not alter the continue token: for a paginated
+// list, the continue token of a truncated page still points after the last object of the page, so
+// clients following it skip the truncated objects. Clients should request pages with a limit no
+// greater than n instead of relying on truncation. Sorted tables are truncated before sorting.
+func WithMaxRows(n int) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.maxRows = n
+	}
+}
+
+// errMaxRows stops the iteration over a list whose table reached the maximum number of rows.
+var errMaxRows = errors.New("maximum number of table rows reached")
+
+// WithNeverIncludeObject never embeds objects or their metadata in table rows, whatever the
+// includeObject policy requested by the client, for resources such as secrets whose content must
+// not leak through tables.
+func WithNeverIncludeObject() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.neverIncludeObject = true
+	}
+}
+
+// WithStrictCells fails the conversion when a cell is not a string, a number, a boolean or nil, or
+// a slice or a map with string keys of such values. This reports cells that cannot be encoded in a
+// Table, for instance a struct or a channel, while converting instead of while serializing.
+func WithStrictCells() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.strictCells = true
+	}
+}
+
+// WithStrictListMetadata fails the conversion of a list whose list metadata cannot be read, which
+// usually means that its type is not registered correctly. By default the table of such a list has
+// no resourceVersion, selfLink or continue token, and the failure is only logged.
+func WithStrictListMetadata() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.strictListMetadata = true
+	}
+}
+
+// WithRowConditions sets the function computing the conditions of every row. By default rows have
+// no conditions.
+func WithRowConditions(fn RowConditionsFunc) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.rowConditions = fn
+	}
+}
+
+// WithNameFunc sets the function computing the primary name cell of each row, for resources whose
+// identity is better described by more than the object name. The column keeps the "name" format,
+// so clients still treat it as the identifier of the row.
+func WithNameFunc(fn func(runtime.Object) string) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.nameFunc = fn
+	}
+}
+
+// WithColumnDescriptions overrides the descriptions of the columns of the convertor, keyed by
+// column name, for resources that want more specific wording than the generic metadata
+// descriptions. Names that match no column are ignored.
+func WithColumnDescriptions(descriptions map[string]string) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		if c.columnDescriptions == nil {
+			c.columnDescriptions = map[string]string{}
+		}
+		for name, description := range descriptions {
+			c.columnDescriptions[name] = description
+		}
+	}
+}
+
+// WithIncludeColumns only renders the columns of the convertor with the given names, in the order
+// of the convertor, for instance only the Name column of the default columns; label columns
+// requested by clients are still rendered. Since options cannot fail, a name that matches no
+// column of the convertor makes every conversion fail with an error listing the valid names.
+func WithIncludeColumns(names ...string) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.includeColumns = append(c.includeColumns, names...)
+	}
+}
+
+// selectColumns returns the columns whose names are included by WithIncludeColumns, and sets the
+// columns error of c if a name matches none of columns.
+func (c *defaultTableConvertor) selectColumns(columns []column) []column {
+	included := sets.NewString(c.includeColumns...)
+	names := sets.NewString()
+	selected := make([]column, 0, len(c.includeColumns))
+	for _, col := range columns {
+		names.Insert(col.definition.Name)
+		if included.Has(col.definition.Name) {
+			selected = append(selected, col)
+		}
+	}
+	if unknown := included.Difference(names); unknown.Len() > 0 {
+		c.failColumns(fmt.Errorf("unknown columns %q, the columns are %q", unknown.List(), names.List()))
+	}
+	return selected
+}
+
+// failColumns sets the error failing every conversion to err, unless the columns already failed,
+// so that the first error of the options is reported.
+func (c *defaultTableConvertor) failColumns(err error) {
+	if c.columnsErr == nil {
+		c.columnsErr = err
+	}
+}
+
+// WithCompositeName marks the columns with the given names as parts of the identifier of the
+// objects, by setting their format to "name", for resources that are keyed by several columns,
+// such as a namespace and a name, rather than by a single human-friendly name. Clients treat every
+// column of the "name" format as identifying, and server-side sorting breaks ties using them in
+// column order. Names that match no column are ignored.
+func WithCompositeName(columns ...string) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		if c.compositeName == nil {
+			c.compositeName = map[string]bool{}
+		}
+		for _, name := range columns {
+			c.compositeName[name] = true
+		}
+	}
+}
+
+// WithStatusRows renders a *metav1.Status passed to ConvertToTable, for example the result of a
+// delete request, as a table with the Status, Reason and Message columns and a single row. The
+// Status itself is embedded in the row unless the includeObject policy is None. Without this
+// option a Status is not acceptable, since it has no object metadata.
+func WithStatusRows() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.statusRows = true
+	}
+}
+
+// WithClock sets the clock used to compute time-derived cells such as the object age, and the
+// durations reported by WithMetricsRecorder.
+func WithClock(clock clock.Clock) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.clock = clock
+	}
 }
 
 // NewDefaultTableConvertor creates a default convertor; the provided resource is used for error messages
 // if no resource info can be determined from the context passed to ConvertToTable.
-func NewDefaultTableConvertor(defaultQualifiedResource schema.GroupResource) TableConvertor {
-	return defaultTableConvertor{defaultQualifiedResource: defaultQualifiedResource}
+//
+// Objects for which meta.IsListType is true are converted as lists, with one row per item, even
+// if they also have their own object metadata, like aggregated lists that are named objects: such
+// hybrid objects do not get a row of their own, and their object metadata only provides the
+// resourceVersion and selfLink of the table when they have no list metadata.
+func NewDefaultTableConvertor(defaultQualifiedResource schema.GroupResource, opts ...TableConvertorOption) TableConvertor {
+	c := &defaultTableConvertor{
+		defaultQualifiedResource: defaultQualifiedResource,
+		clock:                    clock.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	defaults := DefaultColumns()
+	columns := []column{
+		{
+			definition: defaults[0],
+			cell: func(_ context.Context, obj runtime.Object, m metav1.Object) (interface{}, error) {
+				if c.nameFunc != nil {
+					return c.nameFunc(obj), nil
+				}
+				return m.GetName(), nil
+			},
+		},
+		{
+			definition: c.creationColumn(defaults[1]),
+			cell: func(_ context.Context, _ runtime.Object, m metav1.Object) (interface{}, error) {
+				return c.creationCell(m), nil
+			},
+		},
+	}
+	if c.showNamespace {
+		namespaceColumn := column{
+			definition: metav1.TableColumnDefinition{Name: "Namespace", Type: "string", Description: metadataDescriptions()["namespace"]},
+			cell: func(_ context.Context, _ runtime.Object, m metav1.Object) (interface{}, error) {
+				return m.GetNamespace(), nil
+			},
+			include: func(ctx context.Context) bool {
+				info, ok := genericapirequest.RequestInfoFrom(ctx)
+				return !ok || len(info.Namespace) == 0
+			},
+		}
+		columns = append([]column{namespaceColumn}, columns...)
+	}
+	c.setColumns(columns)
+	c.setVersionedColumns()
+	c.defaultColumnsOnly = c.defaultColumnsWarning && c.onlyDefaultColumns(columns)
+	return c
 }
 
-var swaggerMetadataDescriptions = metav1.ObjectMeta{}.SwaggerDoc()
+// NewListMetaTableConvertor creates a default convertor for list types whose list metadata cannot
+// be read by meta.ListAccessor, such as aggregated lists that only expose an object metadata. The
+// list metadata of converted lists, including the continue token and the remaining item count, is
+// read with listMeta instead, and the conversion fails if listMeta returns an error. The metadata
+// of single objects is read as with NewDefaultTableConvertor.
+func NewListMetaTableConvertor(defaultQualifiedResource schema.GroupResource, listMeta func(runtime.Object) (metav1.ListInterface, error), opts ...TableConvertorOption) TableConvertor {
+	opts = append(opts[:len(opts):len(opts)], func(c *defaultTableConvertor) {
+		c.listMeta = listMeta
+	})
+	return NewDefaultTableConvertor(defaultQualifiedResource, opts...)
+}
 
-func (c defaultTableConvertor) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
-	var table metav1.Table
-	fn := func(obj runtime.Object) error {
-		m, err := meta.Accessor(obj)
+// NewDefaultTableConvertorWithAge creates a default convertor that renders an "Age" column instead
+// of the creation timestamp.
+func NewDefaultTableConvertorWithAge(defaultQualifiedResource schema.GroupResource) TableConvertor {
+	return NewDefaultTableConvertor(defaultQualifiedResource, WithAgeColumn())
+}
+
+// NewDefaultTableConvertorWithRowConditions creates a default convertor that sets the conditions
+// of every row with fn.
+func NewDefaultTableConvertorWithRowConditions(defaultQualifiedResource schema.GroupResource, fn RowConditionsFunc) TableConvertor {
+	return NewDefaultTableConvertor(defaultQualifiedResource, WithRowConditions(fn))
+}
+
+// NewDefaultTableConvertorWithClock creates a default convertor that reads the current time from
+// the provided clock, allowing tests to render time-derived cells deterministically.
+func NewDefaultTableConvertorWithClock(defaultQualifiedResource schema.GroupResource, clock clock.Clock) TableConvertor {
+	return NewDefaultTableConvertor(defaultQualifiedResource, WithClock(clock))
+}
+
+var (
+	metadataDescriptionsOnce sync.Once
+	metadataDescriptionsMap  map[string]string
+)
+
+// metadataDescriptions returns the descriptions of the object metadata fields used for the default
+// columns, loading them from the ObjectMeta swagger documentation on first use.
+func metadataDescriptions() map[string]string {
+	metadataDescriptionsOnce.Do(func() {
+		metadataDescriptionsMap = metav1.ObjectMeta{}.SwaggerDoc()
+	})
+	return metadataDescriptionsMap
+}
+
+// SetMetadataDescriptions replaces the descriptions of the object metadata fields, keyed by their
+// JSON field name, that describe the default columns of convertors created afterwards. It is meant
+// to be called during initialization and is not safe to call concurrently with table conversion.
+func SetMetadataDescriptions(descriptions map[string]string) {
+	metadataDescriptionsOnce.Do(func() {})
+	metadataDescriptionsMap = descriptions
+}
+
+// DefaultColumns returns the columns rendered by a default convertor without options, in order:
+// the object name, identified by the "name" format, followed by its creation timestamp. Every call
+// returns a new slice.
+func DefaultColumns() []metav1.TableColumnDefinition {
+	return []metav1.TableColumnDefinition{
+		{Name: "Name", Type: "string", Format: "name", Description: metadataDescriptions()["name"]},
+		{Name: "Created At", Type: "date", Description: metadataDescriptions()["creationTimestamp"]},
+	}
+}
+
+// cancellationCheckInterval is the number of items converted between checks of the context, so
+// that conversions of large lists stop soon after the request is abandoned.
+const cancellationCheckInterval = 100
+
+// maxCellBlockRows is the maximum number of rows whose cells are allocated at once.
+const maxCellBlockRows = 64
+
+// ErrInvalidTableOptions is returned when the table options passed to ConvertToTable are not
+// nil and neither a *metav1.TableOptions nor an *ExtendedTableOptions.
+var ErrInvalidTableOptions = errors.New("table options must be a *v1.TableOptions or *rest.ExtendedTableOptions")
+
+// ExtendedTableOptions are table options with additional presentation settings understood by the
+// default table convertor.
+type ExtendedTableOptions struct {
+	metav1.TableOptions
+
+	// LabelColumns are label keys rendered as additional columns after the built-in ones, like
+	// kubectl --label-columns. Objects without the label render an empty cell.
+	LabelColumns []string
+	// ShowLabels renders a Labels column with all the labels of objects after the label columns,
+	// like kubectl --show-labels, formatted with FormatMapCell.
+	ShowLabels bool
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *ExtendedTableOptions) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &ExtendedTableOptions{TableOptions: *in.TableOptions.DeepCopy(), ShowLabels: in.ShowLabels}
+	if in.LabelColumns != nil {
+		out.LabelColumns = make([]string, len(in.LabelColumns))
+		copy(out.LabelColumns, in.LabelColumns)
+	}
+	return out
+}
+
+// extendedTableOptionsFrom returns the table options passed to ConvertToTable, defaulting them
+// when nil.
+func extendedTableOptionsFrom(tableOptions runtime.Object) (*ExtendedTableOptions, error) {
+	switch t := tableOptions.(type) {
+	case nil:
+		return &ExtendedTableOptions{}, nil
+	case *metav1.TableOptions:
+		if t == nil {
+			return &ExtendedTableOptions{}, nil
+		}
+		return &ExtendedTableOptions{TableOptions: *t}, nil
+	case *ExtendedTableOptions:
+		if t == nil {
+			return &ExtendedTableOptions{}, nil
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("%w, got %T", ErrInvalidTableOptions, tableOptions)
+	}
+}
+
+// labelColumn returns a column rendering the value of the label with the given key.
+func labelColumn(key string) column {
+	return column{
+		definition: metav1.TableColumnDefinition{Name: key, Type: "string", Priority: 1, Description: fmt.Sprintf("The value of the %s label.", key)},
+		cell: func(_ context.Context, _ runtime.Object, m metav1.Object) (interface{}, error) {
+			return m.GetLabels()[key], nil
+		},
+	}
+}
+
+// labelsColumn returns a column rendering all the labels of objects.
+func labelsColumn() column {
+	return column{
+		definition: metav1.TableColumnDefinition{Name: "Labels", Type: "string", Description: metadataDescriptions()["labels"]},
+		cell: func(_ context.Context, _ runtime.Object, m metav1.Object) (interface{}, error) {
+			return FormatMapCell(m.GetLabels()), nil
+		},
+	}
+}
+
+// ConvertToTable implements TableConvertor. The conversions of traced requests are recorded in a
+// span, as are the chunks of ConvertToTableChunk.
+func (c *defaultTableConvertor) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (table *metav1.Table, err error) {
+	ctx, span := c.startConversionSpan(ctx, "ConvertToTable")
+	defer func() { endConversionSpan(span, table, err) }()
+	if table, err = c.convertToTable(ctx, object, tableOptions, true); err != nil || !c.dropEmptyColumns {
+		return table, err
+	}
+	dropEmptyColumns(table)
+	return table, nil
+}
+
+// ConvertToTableChunk implements TableChunkConvertor.
+func (c *defaultTableConvertor) ConvertToTableChunk(ctx context.Context, object runtime.Object, tableOptions runtime.Object, isFirst bool) (table *metav1.Table, err error) {
+	ctx, span := c.startConversionSpan(ctx, "ConvertToTableChunk")
+	defer func() { endConversionSpan(span, table, err) }()
+	return c.convertToTable(ctx, object, tableOptions, isFirst)
+}
+
+// ConvertToTableChunk converts object as one chunk of a stream of tables, such as the events of a
+// watch. Column definitions are only returned for the first chunk. Convertors that do not
+// implement TableChunkConvertor are called through ConvertToTable and have their column
+// definitions removed from subsequent chunks.
+func ConvertToTableChunk(ctx context.Context, c TableConvertor, object runtime.Object, tableOptions runtime.Object, isFirst bool) (*metav1.Table, error) {
+	if chunker, ok := c.(TableChunkConvertor); ok {
+		return chunker.ConvertToTableChunk(ctx, object, tableOptions, isFirst)
+	}
+	table, err := c.ConvertToTable(ctx, object, tableOptions)
+	if err != nil {
+		return nil, err
+	}
+	if !isFirst {
+		table.ColumnDefinitions = nil
+	}
+	return table, nil
+}
+
+// ConvertToTableStream implements TableStreamConvertor.
+func (c *defaultTableConvertor) ConvertToTableStream(ctx context.Context, object runtime.Object, tableOptions runtime.Object, emit func(metav1.TableRow) error) (*metav1.Table, error) {
+	c = c.forRequest(ctx)
+	if c.metrics == nil {
+		return c.streamTable(ctx, object, tableOptions, true, emit)
+	}
+	start := c.clock.Now()
+	rows := 0
+	table, err := c.streamTable(ctx, object, tableOptions, true, func(row metav1.TableRow) error {
+		if err := emit(row); err != nil {
+			return err
+		}
+		rows++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.observeConversion(ctx, start, rows)
+	return table, nil
+}
+
+func (c *defaultTableConvertor) convertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object, includeHeaders bool) (*metav1.Table, error) {
+	c = c.forRequest(ctx)
+	var start time.Time
+	if c.metrics != nil {
+		start = c.clock.Now()
+	}
+	if table, ok := object.(*metav1.Table); ok {
+		opt, err := extendedTableOptionsFrom(tableOptions)
 		if err != nil {
-			resource := c.defaultQualifiedResource
-			if info, ok := genericapirequest.RequestInfoFrom(ctx); ok {
-				resource = schema.GroupResource{Group: info.APIGroup, Resource: info.Resource}
-			}
-			return errNotAcceptable{resource: resource}
+			return nil, err
+		}
+		table, err := c.passthroughTable(ctx, table, opt, includeHeaders, c.includeObjectPolicy(opt), c.tableVersionFor(ctx))
+		if err != nil {
+			return nil, err
 		}
-		table.Rows = append(table.Rows, metav1.TableRow{
-			Cells:  []interface{}{m.GetName(), m.GetCreationTimestamp().Time.UTC().Format(time.RFC3339)},
-			Object: runtime.RawExtension{Object: obj},
-		})
+		c.observeConversion(ctx, start, len(table.Rows))
+		return table, nil
+	}
+	var table *metav1.Table
+	var err error
+	if meta.IsListType(object) && listLen(object) == 0 {
+		// empty lists, like the frequent "no resources found" responses, have no rows to collect
+		if table, err = c.streamTable(ctx, object, tableOptions, includeHeaders, discardRows); err != nil {
+			return nil, err
+		}
+	} else if table, err = c.collectRows(ctx, object, tableOptions, includeHeaders); err != nil {
+		return nil, err
+	}
+	if c.validate {
+		if err := ValidateTable(table); err != nil {
+			return nil, err
+		}
+	}
+	c.observeConversion(ctx, start, len(table.Rows))
+	return table, nil
+}
+
+// collectRows converts object to a table holding its rows, sorted if the convertor has a sort
+// column.
+func (c *defaultTableConvertor) collectRows(ctx context.Context, object runtime.Object, tableOptions runtime.Object, includeHeaders bool) (*metav1.Table, error) {
+	var rows []metav1.TableRow
+	if c.pooledRows {
+		rows = pooledRows()
+	} else if n := rowsHint(object, c.maxRows); n > 0 {
+		rows = make([]metav1.TableRow, 0, n)
+	}
+	table, err := c.streamTable(ctx, object, tableOptions, includeHeaders, func(row metav1.TableRow) error {
+		rows = append(rows, row)
 		return nil
+	})
+	if err != nil {
+		if c.pooledRows {
+			releaseRows(rows)
+		}
+		return nil, err
 	}
-	switch {
-	case meta.IsListType(object):
-		if err := meta.EachListItem(object, fn); err != nil {
+	if len(c.sortColumn) > 0 {
+		opt, err := extendedTableOptionsFrom(tableOptions)
+		if err != nil {
+			return nil, err
+		}
+		_, definitions := c.columnsFor(ctx, opt)
+		c.sortRows(definitions, rows)
+	}
+	if c.pooledRows && len(rows) == 0 {
+		// empty tables have no rows, as without pooling
+		releaseRows(rows)
+		rows = nil
+	}
+	table.Rows = rows
+	return table, nil
+}
+
+// rowsHint returns the number of rows expected for object: one for a single object, or the length
+// of a list, at most maxRows if it is positive. It returns 0 for lists whose length cannot be read,
+// whose rows are appended to a slice that grows as needed.
+func rowsHint(object runtime.Object, maxRows int) int {
+	if !meta.IsListType(object) {
+		return 1
+	}
+	n := listLen(object)
+	if maxRows > 0 && n > maxRows {
+		n = maxRows
+	}
+	return n
+}
+
+// listLen returns the number of items of the list object. Unlike meta.LenList, it counts the
+// items of unstructured lists, which have no Items field.
+func listLen(object runtime.Object) int {
+	if _, ok := object.(runtime.Unstructured); !ok {
+		return meta.LenList(object)
+	}
+	n := 0
+	if err := meta.EachListItem(object, func(runtime.Object) error {
+		n++
+		return nil
+	}); err != nil {
+		return 0
+	}
+	return n
+}
+
+// discardRows is the emit function of conversions that produce no rows.
+func discardRows(metav1.TableRow) error {
+	return nil
+}
+
+// passthroughTable returns a table that was already converted, for instance by a caching layer,
+// instead of treating it as an object to convert. The rows are returned in a new slice so that
+// callers can modify them, with their embedded objects reduced to the includeObject policy: removed
+// for None, and reduced to their metadata at the group version gv for Metadata, and with the
+// columns redacted for the request in ctx masked. The column definitions are omitted when
+// headers are not requested.
+func (c *defaultTableConvertor) passthroughTable(ctx context.Context, table *metav1.Table, opt *ExtendedTableOptions, includeHeaders bool, includeObject metav1.IncludeObjectPolicy, gv schema.GroupVersion) (*metav1.Table, error) {
+	if c.validate {
+		if err := ValidateTable(table); err != nil {
 			return nil, err
 		}
+	}
+	out := *table
+	out.Rows = append([]metav1.TableRow(nil), table.Rows...)
+	c.redactRows(ctx, table.ColumnDefinitions, out.Rows)
+	switch includeObject {
+	case metav1.IncludeNone:
+		for i := range out.Rows {
+			out.Rows[i].Object = runtime.RawExtension{}
+		}
+	case metav1.IncludeMetadata:
+		partialType := partialObjectMetadataType(gv)
+		for i := range out.Rows {
+			out.Rows[i].Object = embeddedMetadata(out.Rows[i].Object, partialType)
+		}
+	case metav1.IncludeObject:
+	default:
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("unrecognized includeObject value: %q", includeObject))
+	}
+	if !includeHeaders || opt.NoHeaders {
+		out.ColumnDefinitions = nil
+	}
+	return &out, nil
+}
+
+// embeddedMetadata returns the metadata of the object embedded in ext as a PartialObjectMetadata
+// of type partialType. Embedded objects whose metadata cannot be read, for instance objects
+// encoded as protobuf, are removed rather than passed on in full.
+func embeddedMetadata(ext runtime.RawExtension, partialType metav1.TypeMeta) runtime.RawExtension {
+	var partial *metav1.PartialObjectMetadata
+	switch {
+	case ext.Object != nil:
+		m, err := meta.Accessor(ext.Object)
+		if err != nil {
+			return runtime.RawExtension{}
+		}
+		partial = meta.AsPartialObjectMetadata(m)
+	case len(ext.Raw) > 0:
+		partial = &metav1.PartialObjectMetadata{}
+		if err := json.Unmarshal(ext.Raw, partial); err != nil {
+			return runtime.RawExtension{}
+		}
 	default:
-		if err := fn(object); err != nil {
+		return ext
+	}
+	partial.TypeMeta = partialType
+	return runtime.RawExtension{Object: partial}
+}
+
+// includeObjectPolicy returns the policy of the objects embedded in rows for the options opt:
+// the requested policy, Metadata by default, or None if the convertor never includes objects.
+func (c *defaultTableConvertor) includeObjectPolicy(opt *ExtendedTableOptions) metav1.IncludeObjectPolicy {
+	if c.neverIncludeObject {
+		return metav1.IncludeNone
+	}
+	if len(opt.IncludeObject) > 0 {
+		return opt.IncludeObject
+	}
+	return metav1.IncludeMetadata
+}
+
+// streamTable converts object to rows and passes them to emit in order, stopping at the first
+// error. The returned table carries the list metadata and column definitions, but no rows.
+func (c *defaultTableConvertor) streamTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object, includeHeaders bool, emit func(metav1.TableRow) error) (*metav1.Table, error) {
+	tableVersion := c.tableVersionFor(ctx)
+	table := metav1.Table{TypeMeta: tableTypeMeta(tableVersion)}
+	opt, err := extendedTableOptionsFrom(tableOptions)
+	if err != nil {
+		return nil, err
+	}
+	includeObject := c.includeObjectPolicy(opt)
+	if existing, ok := object.(*metav1.Table); ok {
+		table, err := c.passthroughTable(ctx, existing, opt, includeHeaders, includeObject, tableVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range table.Rows {
+			if err := emit(row); err != nil {
+				return nil, err
+			}
+		}
+		table.Rows = nil
+		return table, nil
+	}
+	if status, ok := object.(*metav1.Status); ok && c.statusRows {
+		table, err := statusTable(status, opt, includeHeaders, includeObject, emit)
+		if err != nil {
 			return nil, err
 		}
+		table.TypeMeta = tableTypeMeta(tableVersion)
+		return table, nil
 	}
-	if m, err := meta.ListAccessor(object); err == nil {
+	if c.columnsErr != nil {
+		return nil, c.columnsErr
+	}
+	columns, definitions := c.columnsFor(ctx, opt)
+	if c.defaultColumnsOnly {
+		warnDefaultColumns(ctx, c.defaultQualifiedResource)
+	}
+	// lists take precedence over objects: the object metadata of a list, if any, is not a row
+	isList := meta.IsListType(object)
+	// items counts the visited objects
+	items, truncated := 0, false
+	// empty lists, like the frequent "no resources found" responses, have no rows to emit
+	if !isList || listLen(object) > 0 {
+		if items, truncated, err = c.emitRows(ctx, object, isList, columns, includeObject, tableVersion, emit); err != nil {
+			return nil, err
+		}
+	}
+	// the list metadata of the table is copied on a best-effort basis: the resourceVersion and
+	// selfLink of lists and objects, and the continue token and remaining item count of lists
+	var listMeta metav1.ListInterface
+	if isList && c.listMeta != nil {
+		if listMeta, err = c.listMeta(object); err != nil {
+			return nil, fmt.Errorf("unable to read the list metadata of %T: %v", object, err)
+		}
+	} else {
+		listMeta, err = meta.ListAccessor(object)
+	}
+	if err == nil {
+		table.ResourceVersion = listMeta.GetResourceVersion()
+		table.SelfLink = listMeta.GetSelfLink()
+		table.Continue = listMeta.GetContinue()
+		// the remaining item count is only meaningful for a chunk of a paginated list, and cannot
+		// be trusted when rows are filtered
+		if len(table.Continue) > 0 && c.rowFilter == nil {
+			table.RemainingItemCount = listMeta.GetRemainingItemCount()
+		}
+	} else if m, err := meta.CommonAccessor(object); err == nil {
 		table.ResourceVersion = m.GetResourceVersion()
 		table.SelfLink = m.GetSelfLink()
-		table.Continue = m.GetContinue()
-		table.RemainingItemCount = m.GetRemainingItemCount()
-	} else {
-		if m, err := meta.CommonAccessor(object); err == nil {
-			table.ResourceVersion = m.GetResourceVersion()
-			table.SelfLink = m.GetSelfLink()
+	} else if isList {
+		if c.strictListMetadata {
+			return nil, fmt.Errorf("unable to read the list metadata of %T: %v", object, err)
 		}
+		klog.V(2).InfoS("Unable to read the list metadata of an object converted to a Table", "type", fmt.Sprintf("%T", object), "err", err)
 	}
-	if opt, ok := tableOptions.(*metav1.TableOptions); !ok || !opt.NoHeaders {
-		table.ColumnDefinitions = []metav1.TableColumnDefinition{
-			{Name: "Name", Type: "string", Format: "name", Description: swaggerMetadataDescriptions["name"]},
-			{Name: "Created At", Type: "date", Description: swaggerMetadataDescriptions["creationTimestamp"]},
+	if truncated {
+		// the remaining item count is only known if the list is complete or carries its own count
+		if c.rowFilter == nil && (len(table.Continue) == 0 || table.RemainingItemCount != nil) {
+			remaining := int64(listLen(object) - items + 1)
+			if table.RemainingItemCount != nil {
+				remaining += *table.RemainingItemCount
+			}
+			table.RemainingItemCount = &remaining
 		}
+		resource := requestResource(ctx, c.defaultQualifiedResource)
+		warning.AddWarning(ctx, "", fmt.Sprintf("the table of %s was truncated to %d rows", resource, c.maxRows))
+	}
+	if includeHeaders && !opt.NoHeaders {
+		table.ColumnDefinitions = make([]metav1.TableColumnDefinition, len(definitions))
+		copy(table.ColumnDefinitions, definitions)
 	}
 	return &table, nil
 }
 
+// emitRows converts object, or its items if isList, to rows of the given columns and passes them
+// to emit in order, stopping at the first error. Embedded metadata has the group version of the
+// table. It returns the number of visited objects and whether the rows were truncated by
+// WithMaxRows.
+func (c *defaultTableConvertor) emitRows(ctx context.Context, object runtime.Object, isList bool, columns []column, includeObject metav1.IncludeObjectPolicy, tableVersion schema.GroupVersion, emit func(metav1.TableRow) error) (items int, truncated bool, err error) {
+	encoder, encode := embeddedObjectEncoderFrom(ctx)
+	ctx, content := withRowContentCache(ctx)
+	// the type of embedded metadata is the same for every row
+	partialType := partialObjectMetadataType(tableVersion)
+	// the cells of consecutive rows are carved from blocks of growing size, which saves an
+	// allocation per row of large lists without over-allocating for single objects
+	var cellBlock []interface{}
+	blockRows := 1
+	rows, skipped := 0, 0
+	fn := func(obj runtime.Object) error {
+		if items%cancellationCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		items++
+		if c.maxRows > 0 && rows >= c.maxRows {
+			truncated = true
+			return errMaxRows
+		}
+		if c.rowFilter != nil && !c.rowFilter(obj) {
+			return nil
+		}
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			if isList && c.skipInaccessible {
+				klog.V(4).InfoS("Skipping an item without object metadata in a Table conversion", "type", fmt.Sprintf("%T", obj), "err", err)
+				skipped++
+				return nil
+			}
+			return newNotAcceptableError(ctx, c.defaultQualifiedResource)
+		}
+		if len(cellBlock) < len(columns) {
+			cellBlock = make([]interface{}, len(columns)*blockRows)
+			if blockRows < maxCellBlockRows {
+				blockRows *= 2
+			}
+		}
+		// the capacity of the cells is limited so that appending to them does not overwrite the
+		// cells of the next row
+		cells := cellBlock[:0:len(columns)]
+		cellBlock = cellBlock[len(columns):]
+		content.reset(obj)
+		var cellErrs []error
+		// typeValue is the cell of the type column of the row, if it is rendered
+		var typeValue interface{}
+		typeRendered, typeRedacted := false, false
+		for _, col := range columns {
+			cell, err := col.cell(ctx, obj, m)
+			if err == nil && c.strictCells {
+				if err = validateCell(cell); err != nil {
+					err = fmt.Errorf("column %q of %s: %v", col.definition.Name, m.GetName(), err)
+				}
+			}
+			if err != nil {
+				if !c.bestEffort {
+					return err
+				}
+				cellErrs = append(cellErrs, err)
+				cell = nil
+			} else if col.warningType {
+				typeValue, typeRendered, typeRedacted = cell, true, col.redacted
+			}
+			if c.sanitizeStrings {
+				cell = sanitizeCell(cell)
+			}
+			cells = append(cells, cell)
+		}
+		object, err := embeddedObject(obj, m, includeObject, partialType)
+		if err != nil {
+			return err
+		}
+		if includeObject == metav1.IncludeObject && c.objectConvertor != nil {
+			if object.Object, err = c.objectConvertor.ConvertToVersion(obj, c.objectVersion); err != nil {
+				return err
+			}
+		} else if includeObject == metav1.IncludeObject && c.scheme != nil {
+			if object.Object, err = externalObject(c.scheme, obj); err != nil {
+				return err
+			}
+		}
+		if encode {
+			if object, err = encodeEmbeddedObject(object, encoder); err != nil {
+				return err
+			}
+		}
+		if includeObject == metav1.IncludeObject && c.maxEmbeddedObjectBytes > 0 {
+			if object, err = c.limitEmbeddedObject(object, m, tableVersion, encoder, encode); err != nil {
+				return err
+			}
+		}
+		if c.canonicalEmbeddedObjects && len(object.Raw) > 0 {
+			if object.Raw, err = canonicalJSON(object.Raw); err != nil {
+				return err
+			}
+		}
+		row := metav1.TableRow{
+			Cells:  cells,
+			Object: object,
+		}
+		if c.rowConditions != nil {
+			row.Conditions = c.rowConditions(obj)
+		}
+		if c.terminalPhases.Len() > 0 {
+			if phase := statusString(ctx, obj, "phase"); c.terminalPhases.Has(phase) {
+				row.Conditions = append(row.Conditions, completedCondition(phase))
+			}
+		}
+		// the type of redacted type columns is not leaked through the conditions: rows whose type
+		// column is not rendered only warn if no request has the column redacted
+		if c.warningTypes.Len() > 0 && !typeRedacted && (typeRendered || !c.redacts(typeColumnName)) {
+			if !typeRendered {
+				if value, err := c.typeCell(ctx, obj, m); err == nil {
+					typeValue = value
+				}
+			}
+			if t, ok := typeValue.(string); ok && c.warningTypes.Has(t) {
+				row.Conditions = append(row.Conditions, warningCondition(t))
+			}
+		}
+		if len(cellErrs) > 0 {
+			row.Conditions = append(row.Conditions, metav1.TableRowCondition{
+				Type:    RowConversionFailed,
+				Status:  metav1.ConditionTrue,
+				Reason:  "CellError",
+				Message: utilerrors.NewAggregate(cellErrs).Error(),
+			})
+		}
+		if err := emit(row); err != nil {
+			return err
+		}
+		rows++
+		return nil
+	}
+	if isList {
+		err = meta.EachListItem(object, fn)
+	} else {
+		err = fn(object)
+	}
+	if err == errMaxRows {
+		err = nil
+	}
+	if skipped > 0 {
+		resource := requestResource(ctx, c.defaultQualifiedResource)
+		warning.AddWarning(ctx, "", fmt.Sprintf("items of %s without object metadata omitted from the table: %d", resource, skipped))
+	}
+	return items, truncated, err
+}
+
+var statusColumns = []metav1.TableColumnDefinition{
+	{Name: "Status", Type: "string", Description: metav1.Status{}.SwaggerDoc()["status"]},
+	{Name: "Reason", Type: "string", Description: metav1.Status{}.SwaggerDoc()["reason"]},
+	{Name: "Message", Type: "string", Description: metav1.Status{}.SwaggerDoc()["message"]},
+}
+
+// statusTable emits the single row of a table describing status.
+func statusTable(status *metav1.Status, opt *ExtendedTableOptions, includeHeaders bool, includeObject metav1.IncludeObjectPolicy, emit func(metav1.TableRow) error) (*metav1.Table, error) {
+	row := metav1.TableRow{Cells: []interface{}{status.Status, string(status.Reason), status.Message}}
+	if includeObject != metav1.IncludeNone {
+		row.Object = runtime.RawExtension{Object: status}
+	}
+	if err := emit(row); err != nil {
+		return nil, err
+	}
+	table := &metav1.Table{ListMeta: metav1.ListMeta{ResourceVersion: status.ResourceVersion, SelfLink: status.SelfLink}}
+	if includeHeaders && !opt.NoHeaders {
+		table.ColumnDefinitions = make([]metav1.TableColumnDefinition, len(statusColumns))
+		copy(table.ColumnDefinitions, statusColumns)
+	}
+	return table, nil
+}
+
+// setColumns sets the columns of the convertor, followed by the extra columns added by options,
+// and precomputes their definitions.
+func (c *defaultTableConvertor) setColumns(columns []column) {
+	placed := len(c.columnsBefore)+len(c.columnsAfter) > 0
+	if placed {
+		columns = append(append(append([]column{}, c.columnsBefore...), columns...), c.columnsAfter...)
+	}
+	columns = append(columns, c.extraColumns...)
+	if c.kindColumn {
+		columns = append([]column{c.newKindColumn()}, columns...)
+	}
+	if placed {
+		if duplicates := duplicateColumnNames(columns); len(duplicates) > 0 {
+			c.failColumns(fmt.Errorf("duplicate columns %q", duplicates))
+		}
+	}
+	if len(c.includeColumns) > 0 {
+		columns = c.selectColumns(columns)
+	}
+	for i := range columns {
+		if len(columns[i].definition.Description) == 0 && c.openAPISchema != nil {
+			columns[i].definition.Description = openAPIDescription(c.openAPISchema, columns[i])
+		}
+		if description, ok := c.columnDescriptions[columns[i].definition.Name]; ok {
+			columns[i].definition.Description = description
+		}
+		if columns[i].timestamp && c.showAge {
+			columns[i].definition.Type = "string"
+		}
+		if c.compositeName[columns[i].definition.Name] {
+			columns[i].definition.Format = "name"
+		}
+	}
+	c.columns = columns
+	c.definitions = columnDefinitions(columns)
+	c.conditionalColumns = false
+	for _, col := range columns {
+		if col.include != nil {
+			c.conditionalColumns = true
+		}
+	}
+}
+
+// columnsFor returns the columns rendered for the request in ctx with the given options, and their
+// definitions. The returned definitions must not be modified.
+func (c *defaultTableConvertor) columnsFor(ctx context.Context, opt *ExtendedTableOptions) ([]column, []metav1.TableColumnDefinition) {
+	if !c.conditionalColumns && len(opt.LabelColumns) == 0 && !opt.ShowLabels {
+		if len(c.redactions) > 0 {
+			return c.redactColumns(ctx, c.columns), c.definitions
+		}
+		return c.columns, c.definitions
+	}
+	columns := make([]column, 0, len(c.columns)+len(opt.LabelColumns))
+	for _, col := range c.columns {
+		if col.include == nil || col.include(ctx) {
+			columns = append(columns, col)
+		}
+	}
+	for _, key := range opt.LabelColumns {
+		columns = append(columns, labelColumn(key))
+	}
+	if opt.ShowLabels {
+		columns = append(columns, labelsColumn())
+	}
+	if len(c.redactions) > 0 {
+		columns = c.redactColumns(ctx, columns)
+	}
+	return columns, columnDefinitions(columns)
+}
+
+func columnDefinitions(columns []column) []metav1.TableColumnDefinition {
+	definitions := make([]metav1.TableColumnDefinition, 0, len(columns))
+	for _, col := range columns {
+		definitions = append(definitions, col.definition)
+	}
+	return definitions
+}
+
+// embeddedObject returns the object to embed in a table row according to the requested policy.
+// Metadata is embedded as a PartialObjectMetadata of type partialType so that clients can decode
+// it.
+func embeddedObject(obj runtime.Object, m metav1.Object, policy metav1.IncludeObjectPolicy, partialType metav1.TypeMeta) (runtime.RawExtension, error) {
+	switch policy {
+	case metav1.IncludeNone:
+		return runtime.RawExtension{}, nil
+	case metav1.IncludeObject:
+		return runtime.RawExtension{Object: obj}, nil
+	case metav1.IncludeMetadata:
+		partial := meta.AsPartialObjectMetadata(m)
+		partial.TypeMeta = partialType
+		return runtime.RawExtension{Object: partial}, nil
+	default:
+		return runtime.RawExtension{}, apierrors.NewBadRequest(fmt.Sprintf("unrecognized includeObject value: %q", policy))
+	}
+}
+
+// partialObjectMetadataType returns the type of the metadata embedded in tables of the group
+// version gv.
+func partialObjectMetadataType(gv schema.GroupVersion) metav1.TypeMeta {
+	return metav1.TypeMeta{APIVersion: gv.String(), Kind: "PartialObjectMetadata"}
+}
+
+// externalObject returns obj converted to the preferred version of its group in scheme if its kind
+// is internal, a copy of obj with its kind set if it has no type metadata, and obj otherwise.
+func externalObject(scheme *runtime.Scheme, obj runtime.Object) (runtime.Object, error) {
+	gvks, _, err := scheme.ObjectKinds(obj)
+	if err != nil {
+		if runtime.IsNotRegisteredError(err) {
+			return obj, nil
+		}
+		return nil, err
+	}
+	gvk := gvks[0]
+	if gvk.Version == runtime.APIVersionInternal {
+		versions := scheme.PrioritizedVersionsForGroup(gvk.Group)
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("no external version of %s is registered", gvk.GroupKind())
+		}
+		return scheme.ConvertToVersion(obj, versions[0])
+	}
+	if obj.GetObjectKind().GroupVersionKind().Empty() {
+		obj = obj.DeepCopyObject()
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	return obj, nil
+}
+
+func (c *defaultTableConvertor) creationColumn(createdAt metav1.TableColumnDefinition) metav1.TableColumnDefinition {
+	if c.showAge {
+		return metav1.TableColumnDefinition{Name: "Age", Type: "string", Description: createdAt.Description}
+	}
+	return createdAt
+}
+
+func (c *defaultTableConvertor) creationCell(m metav1.Object) interface{} {
+	return c.timestampCell(m.GetCreationTimestamp())
+}
+
+// timestampCell returns the cell of a timestamp column for timestamp.
+func (c *defaultTableConvertor) timestampCell(timestamp metav1.Time) interface{} {
+	if timestamp.IsZero() {
+		// missing timestamps, like the creation timestamp of objects that were never persisted,
+		// must not render as the zero time
+		if c.showAge {
+			return "<unknown>"
+		}
+		return ""
+	}
+	if !c.showAge {
+		if len(c.timestampLayout) > 0 {
+			return timestamp.UTC().Format(c.timestampLayout)
+		}
+		// formatted directly rather than with FormatCell, which would box timestamp for every row
+		return timestamp.UTC().Format(time.RFC3339)
+	}
+	if c.ageFormat == nil {
+		return AgeLong(c.clock.Since(timestamp.Time))
+	}
+	return c.ageFormat(c.clock.Since(timestamp.Time))
+}
+
 // errNotAcceptable indicates the resource doesn't support Table conversion
 type errNotAcceptable struct {
 	resource schema.GroupResource
 }
 
+// newNotAcceptableError returns an errNotAcceptable for the resource of the conversion in ctx, see
+// requestResource.
+func newNotAcceptableError(ctx context.Context, defaultQualifiedResource schema.GroupResource) error {
+	return errNotAcceptable{resource: requestResource(ctx, defaultQualifiedResource)}
+}
+
+// WithTableResource returns a copy of parent in which table convertors attribute their
+// conversions to resource, in error messages, warnings and metrics, instead of deriving it from
+// the request info of parent. Callers converting objects outside of a request, or for a resource
+// other than the requested one, should set it, since convertors otherwise fall back to their
+// default resource, which is empty for some convertors.
+func WithTableResource(parent context.Context, resource schema.GroupResource) context.Context {
+	return context.WithValue(parent, tableResourceKey, resource)
+}
+
+// requestResource returns the resource set in ctx by WithTableResource, or the resource of the
+// request in ctx, or defaultQualifiedResource if ctx has neither.
+func requestResource(ctx context.Context, defaultQualifiedResource schema.GroupResource) schema.GroupResource {
+	if resource, ok := ctx.Value(tableResourceKey).(schema.GroupResource); ok {
+		return resource
+	}
+	if info, ok := genericapirequest.RequestInfoFrom(ctx); ok {
+		return schema.GroupResource{Group: info.APIGroup, Resource: info.Resource}
+	}
+	return defaultQualifiedResource
+}
+
+// Resource returns the resource that does not support Table conversion.
+func (e errNotAcceptable) Resource() schema.GroupResource {
+	return e.resource
+}
+
+// IsNotAcceptable returns the resource and true if err, or an error it wraps, was returned by a
+// table convertor because the resource does not support being converted to a Table.
+func IsNotAcceptable(err error) (schema.GroupResource, bool) {
+	var notAcceptable errNotAcceptable
+	if errors.As(err, &notAcceptable) {
+		return notAcceptable.Resource(), true
+	}
+	return schema.GroupResource{}, false
+}
+
 func (e errNotAcceptable) Error() string {
 	return fmt.Sprintf("the resource %s does not support being converted to a Table", e.resource)
 }
@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// AggOp is an operation aggregating the elements of an array into a count.
+type AggOp string
+
+const (
+	// AggSum sums the integers of the elements.
+	AggSum AggOp = "sum"
+	// AggMax takes the greatest integer of the elements.
+	AggMax AggOp = "max"
+	// AggCount counts the elements that have the field.
+	AggCount AggOp = "count"
+)
+
+// WithAggregatedCountColumn appends an integer column with the given name aggregating the
+// elements of the array at jsonPathToArray with op, such as the sum of the restarts of the
+// containers of pods with ".status.containerStatuses", ".restartCount" and AggSum.
+// jsonPathWithinElement is evaluated against every element: AggSum and AggMax aggregate the
+// integers it finds, and AggCount counts the elements where it finds a value, or all the elements
+// if it is empty. Elements without the field are skipped. Objects without the array render an
+// empty cell, as does AggMax for an array without values, while AggSum and AggCount render 0.
+// Values that are not integers fail the conversion of the row.
+//
+// Since options cannot fail, a malformed JSONPath or an unknown operation makes every conversion
+// fail.
+func WithAggregatedCountColumn(name, jsonPathToArray, jsonPathWithinElement string, op AggOp) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		switch op {
+		case AggSum, AggMax, AggCount:
+		default:
+			c.failColumns(fmt.Errorf("unknown aggregation %q of column %q", op, name))
+			return
+		}
+		findArray, err := jsonPathFinder(name, jsonPathToArray)
+		if err != nil {
+			c.failColumns(err)
+			return
+		}
+		var findField func(interface{}) []interface{}
+		if len(jsonPathWithinElement) > 0 {
+			if findField, err = jsonPathFinder(name, jsonPathWithinElement); err != nil {
+				c.failColumns(err)
+				return
+			}
+		}
+		description := fmt.Sprintf("The %s of %s over the elements of %s.", op, jsonPathWithinElement, jsonPathToArray)
+		if findField == nil {
+			description = fmt.Sprintf("The %s of the elements of %s.", op, jsonPathToArray)
+		}
+		c.extraColumns = append(c.extraColumns, column{
+			definition: metav1.TableColumnDefinition{Name: name, Type: "integer", Description: description},
+			jsonPath:   jsonPathToArray,
+			cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+				content, err := rowContent(ctx, obj)
+				if err != nil {
+					return nil, err
+				}
+				elements := findArray(content)
+				if len(elements) == 1 {
+					if array, ok := elements[0].([]interface{}); ok {
+						elements = array
+					}
+				} else if elements == nil {
+					return nil, nil
+				}
+				return aggregate(op, elements, findField)
+			},
+		})
+	}
+}
+
+// aggregate returns the aggregation with op of the values findField finds in elements, or of the
+// elements themselves if findField is nil.
+func aggregate(op AggOp, elements []interface{}, findField func(interface{}) []interface{}) (interface{}, error) {
+	var result int64
+	found := false
+	for _, element := range elements {
+		values := []interface{}{element}
+		if findField != nil {
+			values = findField(element)
+		}
+		for _, value := range values {
+			if op == AggCount {
+				result++
+				break
+			}
+			i, ok := FormatCell("integer", "", value).(int64)
+			if !ok {
+				return nil, fmt.Errorf("%v (%T) is not an integer", value, value)
+			}
+			switch {
+			case op == AggSum:
+				result += i
+			case !found || i > result:
+				result = i
+			}
+			found = true
+		}
+	}
+	if op == AggMax && !found {
+		return nil, nil
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWithAggregatedCountColumn(t *testing.T) {
+	statuses := func(statuses ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"status": map[string]interface{}{"containerStatuses": statuses}}
+	}
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("restarted", statuses(
+			map[string]interface{}{"name": "a", "restartCount": int64(2)},
+			map[string]interface{}{"name": "b", "restartCount": int64(5)},
+			map[string]interface{}{"name": "c"},
+		))},
+		{Object: newUnstructured("empty", statuses())},
+		{Object: newUnstructured("none", nil)},
+	}}
+	tests := []struct {
+		name     string
+		field    string
+		op       AggOp
+		expected []interface{}
+	}{
+		{name: "sum", field: ".restartCount", op: AggSum, expected: []interface{}{int64(7), int64(0), nil}},
+		{name: "max", field: ".restartCount", op: AggMax, expected: []interface{}{int64(5), nil, nil}},
+		{name: "count field", field: ".restartCount", op: AggCount, expected: []interface{}{int64(2), int64(0), nil}},
+		{name: "count elements", op: AggCount, expected: []interface{}{int64(3), int64(0), nil}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithAggregatedCountColumn("Restarts", ".status.containerStatuses", tc.field, tc.op))
+			table, err := c.ConvertToTable(context.TODO(), list, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if def := table.ColumnDefinitions[2]; def.Name != "Restarts" || def.Type != "integer" {
+				t.Errorf("unexpected column: %#v", def)
+			}
+			var cells []interface{}
+			for _, row := range table.Rows {
+				cells = append(cells, row.Cells[2])
+			}
+			if !reflect.DeepEqual(tc.expected, cells) {
+				t.Errorf("expected cells %#v, got %#v", tc.expected, cells)
+			}
+		})
+	}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithAggregatedCountColumn("Restarts", ".status.containerStatuses[*]", ".name", AggSum))
+	if _, err := c.ConvertToTable(context.TODO(), list, nil); err == nil {
+		t.Errorf("expected values that are not integers to fail the conversion")
+	}
+	for _, count := range []interface{}{float64(3), 2.5, 1e19} {
+		pod := newUnstructured("float", statuses(map[string]interface{}{"name": "a", "restartCount": count}))
+		c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithAggregatedCountColumn("Restarts", ".status.containerStatuses", ".restartCount", AggSum))
+		table, err := c.ConvertToTable(context.TODO(), pod, nil)
+		if count == float64(3) {
+			if err != nil || table.Rows[0].Cells[2] != int64(3) {
+				t.Errorf("expected an integral float to be summed, got %v, %v", table, err)
+			}
+		} else if err == nil {
+			t.Errorf("%v: expected a float that is not an integer to fail the conversion", count)
+		}
+	}
+	for name, opt := range map[string]TableConvertorOption{
+		"unknown operation":    WithAggregatedCountColumn("Restarts", ".status.containerStatuses", ".restartCount", "avg"),
+		"malformed array path": WithAggregatedCountColumn("Restarts", ".status[[bad", ".restartCount", AggSum),
+		"malformed field path": WithAggregatedCountColumn("Restarts", ".status.containerStatuses", ".restartCount[[bad", AggSum),
+	} {
+		c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, opt)
+		if _, err := c.ConvertToTable(context.TODO(), list, nil); err == nil {
+			t.Errorf("%s: expected the conversion to fail", name)
+		}
+	}
+}
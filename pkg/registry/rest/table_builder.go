@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/clock"
+)
+
+// ColumnBuilder declares the columns of a table convertor. The convertor it builds shares the
+// list and single object handling of the default convertor and fills exactly one cell per
+// column for every row.
+type ColumnBuilder struct {
+	defaultQualifiedResource schema.GroupResource
+	columns                  []column
+	// err is the error of the first column rejected by AddColumn.
+	err error
+}
+
+// NewColumnBuilder returns an empty builder; the provided resource is used for error messages
+// if no resource info can be determined from the context passed to ConvertToTable.
+func NewColumnBuilder(defaultQualifiedResource schema.GroupResource) *ColumnBuilder {
+	return &ColumnBuilder{defaultQualifiedResource: defaultQualifiedResource}
+}
+
+// ColumnExtractor computes the cell of a column for obj, converted for the request in ctx. The
+// request info and the user of the request can be read from ctx, for instance to redact a field
+// for users who are not administrators.
+type ColumnExtractor func(ctx context.Context, obj runtime.Object) (interface{}, error)
+
+// ContextFreeExtractor adapts an extractor that does not depend on the request to a
+// ColumnExtractor.
+func ContextFreeExtractor(extract func(runtime.Object) (interface{}, error)) ColumnExtractor {
+	return func(_ context.Context, obj runtime.Object) (interface{}, error) {
+		return extract(obj)
+	}
+}
+
+// AddColumn appends a column whose cells are computed by extract, with the context passed to
+// ConvertToTable, and normalized with FormatCell. An error returned by extract fails the
+// conversion. A column whose type is not valid according to ValidColumnType is rejected: it is
+// not appended, Err returns an error, and the convertors built afterwards fail every conversion.
+func (b *ColumnBuilder) AddColumn(def metav1.TableColumnDefinition, extract ColumnExtractor) *ColumnBuilder {
+	if err := validateColumnType(def); err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+	b.columns = append(b.columns, column{
+		definition: def,
+		cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+			value, err := extract(ctx, obj)
+			if err != nil {
+				return nil, err
+			}
+			return FormatCell(def.Type, def.Format, value), nil
+		},
+	})
+	return b
+}
+
+// AddArrayColumn appends a multi-valued column, for instance the external IPs of services, whose
+// cells are computed by extract with the context passed to ConvertToTable. The cells of "array"
+// columns hold the values as they are, so that JSON tables render them as arrays, while those of
+// "string" columns hold the values joined for text output by FormatArrayCell. An error returned by
+// extract fails the conversion. A column of another type is rejected as by AddColumn.
+func (b *ColumnBuilder) AddArrayColumn(def metav1.TableColumnDefinition, extract func(ctx context.Context, obj runtime.Object) ([]string, error)) *ColumnBuilder {
+	if def.Type != "array" && def.Type != "string" {
+		if b.err == nil {
+			b.err = fmt.Errorf("column %q of multiple values has the type %q, the types are %q", def.Name, def.Type, []string{"array", "string"})
+		}
+		return b
+	}
+	b.columns = append(b.columns, column{
+		definition: def,
+		cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+			values, err := extract(ctx, obj)
+			if err != nil {
+				return nil, err
+			}
+			if def.Type == "string" {
+				return FormatArrayCell(values), nil
+			}
+			return values, nil
+		},
+	})
+	return b
+}
+
+// AddPrinterColumns appends columns whose cells are extracted with the JSONPath of each printer
+// column, such as the curated sets returned by WorkloadColumns. The columns are rejected as by
+// AddColumn, and none of them is appended, if any of the JSONPath expressions is malformed or any
+// of the types is not valid according to ValidColumnType.
+func (b *ColumnBuilder) AddPrinterColumns(columns ...PrinterColumn) *ColumnBuilder {
+	added := make([]column, 0, len(columns))
+	for _, col := range columns {
+		tableColumn, err := printerColumn(col)
+		if err != nil {
+			if b.err == nil {
+				b.err = err
+			}
+			return b
+		}
+		added = append(added, tableColumn)
+	}
+	b.columns = append(b.columns, added...)
+	return b
+}
+
+// Err returns the error of the first column that was rejected, or nil if every column was
+// appended.
+func (b *ColumnBuilder) Err() error {
+	return b.err
+}
+
+// validateColumnType returns an error if the type of def is not valid.
+func validateColumnType(def metav1.TableColumnDefinition) error {
+	if !ValidColumnType(def.Type) {
+		return fmt.Errorf("column %q has the unknown type %q, the types are %q", def.Name, def.Type, validColumnTypes)
+	}
+	return nil
+}
+
+// Build returns a TableConvertor rendering the columns added so far, customized by opts. Options
+// that change the default Name and creation columns have no effect. Later changes to the builder
+// do not affect convertors that were already built. The convertor fails every conversion with the
+// error returned by Err, if any.
+func (b *ColumnBuilder) Build(opts ...TableConvertorOption) TableConvertor {
+	columns := make([]column, len(b.columns))
+	copy(columns, b.columns)
+	c := &defaultTableConvertor{
+		defaultQualifiedResource: b.defaultQualifiedResource,
+		clock:                    clock.RealClock{},
+		columnsErr:               b.err,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.setColumns(columns)
+	return c
+}
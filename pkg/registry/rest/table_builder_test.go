@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/apis/example"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func podNodeName(obj runtime.Object) (interface{}, error) {
+	pod, ok := obj.(*example.Pod)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T", obj)
+	}
+	return pod.Spec.NodeName, nil
+}
+
+func TestColumnBuilder(t *testing.T) {
+	b := NewColumnBuilder(schema.GroupResource{Resource: "pods"}).
+		AddColumn(metav1.TableColumnDefinition{Name: "Name", Type: "string", Format: "name"}, func(_ context.Context, obj runtime.Object) (interface{}, error) {
+			m, err := meta.Accessor(obj)
+			if err != nil {
+				return nil, err
+			}
+			return m.GetName(), nil
+		}).
+		AddColumn(metav1.TableColumnDefinition{Name: "Node", Type: "string"}, ContextFreeExtractor(podNodeName))
+	c := b.Build()
+	b.AddColumn(metav1.TableColumnDefinition{Name: "Ignored", Type: "string"}, ContextFreeExtractor(podNodeName))
+
+	list := &example.PodList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "10"},
+		Items: []example.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Spec: example.PodSpec{NodeName: "node1"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: example.PodSpec{NodeName: "node2"}},
+		},
+	}
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.ResourceVersion != "10" {
+		t.Errorf("unexpected resource version %q", table.ResourceVersion)
+	}
+	if len(table.ColumnDefinitions) != 2 || table.ColumnDefinitions[1].Name != "Node" {
+		t.Errorf("unexpected columns: %#v", table.ColumnDefinitions)
+	}
+	var cells [][]interface{}
+	for _, row := range table.Rows {
+		if len(row.Cells) != len(table.ColumnDefinitions) {
+			t.Errorf("row has %d cells for %d columns", len(row.Cells), len(table.ColumnDefinitions))
+		}
+		cells = append(cells, row.Cells)
+	}
+	if expected := [][]interface{}{{"a", "node1"}, {"b", "node2"}}; !reflect.DeepEqual(expected, cells) {
+		t.Errorf("expected cells %v, got %v", expected, cells)
+	}
+
+	if _, err := c.ConvertToTable(context.TODO(), &example.ReplicaSet{}, nil); err == nil {
+		t.Errorf("expected extractor error to fail the conversion")
+	}
+}
+
+func TestColumnBuilderRequestContext(t *testing.T) {
+	c := NewColumnBuilder(schema.GroupResource{Resource: "pods"}).
+		AddColumn(metav1.TableColumnDefinition{Name: "Node", Type: "string"}, func(ctx context.Context, obj runtime.Object) (interface{}, error) {
+			if u, ok := genericapirequest.UserFrom(ctx); !ok || !sets.NewString(u.GetGroups()...).Has(user.SystemPrivilegedGroup) {
+				return "<redacted>", nil
+			}
+			return podNodeName(obj)
+		}).
+		Build()
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Spec: example.PodSpec{NodeName: "node1"}}
+
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		expected interface{}
+	}{
+		{name: "anonymous", ctx: context.TODO(), expected: "<redacted>"},
+		{name: "user", ctx: genericapirequest.WithUser(context.TODO(), &user.DefaultInfo{Name: "alice"}), expected: "<redacted>"},
+		{name: "admin", ctx: genericapirequest.WithUser(context.TODO(), &user.DefaultInfo{Name: "admin", Groups: []string{user.SystemPrivilegedGroup}}), expected: "node1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			table, err := c.ConvertToTable(tc.ctx, pod, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cell := table.Rows[0].Cells[0]; cell != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, cell)
+			}
+		})
+	}
+}
+
+func TestColumnBuilderColumnTypes(t *testing.T) {
+	b := NewColumnBuilder(schema.GroupResource{Resource: "pods"})
+	for _, colType := range []string{"string", "integer", "number", "boolean", "date", "array"} {
+		b.AddColumn(metav1.TableColumnDefinition{Name: colType, Type: colType}, ContextFreeExtractor(podNodeName))
+	}
+	if err := b.Err(); err != nil {
+		t.Fatalf("expected every valid type to be accepted, got %v", err)
+	}
+	table, err := b.Build().ConvertToTable(context.TODO(), &example.Pod{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 6 {
+		t.Errorf("expected 6 columns, got %#v", table.ColumnDefinitions)
+	}
+
+	for _, colType := range []string{"str", "", "String", "object", "int"} {
+		b := NewColumnBuilder(schema.GroupResource{Resource: "pods"}).
+			AddColumn(metav1.TableColumnDefinition{Name: "Node", Type: colType}, ContextFreeExtractor(podNodeName)).
+			AddColumn(metav1.TableColumnDefinition{Name: "Name", Type: "string"}, ContextFreeExtractor(podNodeName))
+		expected := fmt.Sprintf(`column "Node" has the unknown type %q, the types are ["array" "boolean" "date" "integer" "number" "string"]`, colType)
+		if err := b.Err(); err == nil || err.Error() != expected {
+			t.Errorf("%q: expected error %q, got %v", colType, expected, err)
+		}
+		if _, err := b.Build().ConvertToTable(context.TODO(), &example.Pod{}, nil); err == nil {
+			t.Errorf("%q: expected the conversions of the built convertor to fail", colType)
+		}
+		if err := NewColumnBuilder(schema.GroupResource{Resource: "pods"}).AddPrinterColumns(PrinterColumn{Name: "Node", Type: colType, JSONPath: ".spec.nodeName"}).Err(); err == nil {
+			t.Errorf("%q: expected the printer column to be rejected", colType)
+		}
+	}
+}
+
+func TestColumnBuilderArrayColumn(t *testing.T) {
+	externalIPs := func(_ context.Context, obj runtime.Object) ([]string, error) {
+		content, err := unstructuredContent(obj)
+		if err != nil {
+			return nil, err
+		}
+		ips, _, err := unstructured.NestedStringSlice(content, "spec", "externalIPs")
+		return ips, err
+	}
+	service := newUnstructured("web", map[string]interface{}{"spec": map[string]interface{}{"externalIPs": []interface{}{"10.0.0.1", "10.0.0.2"}}})
+
+	c := NewColumnBuilder(schema.GroupResource{Resource: "services"}).
+		AddArrayColumn(metav1.TableColumnDefinition{Name: "External IPs", Type: "array"}, externalIPs).
+		AddArrayColumn(metav1.TableColumnDefinition{Name: "External IP", Type: "string"}, externalIPs).
+		Build()
+	table, err := c.ConvertToTable(context.TODO(), service, &metav1.TableOptions{IncludeObject: metav1.IncludeNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []interface{}{[]string{"10.0.0.1", "10.0.0.2"}, "10.0.0.1,10.0.0.2"}; !reflect.DeepEqual(expected, table.Rows[0].Cells) {
+		t.Errorf("expected cells %#v, got %#v", expected, table.Rows[0].Cells)
+	}
+	data, err := json.Marshal(table.Rows[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"cells":[["10.0.0.1","10.0.0.2"],"10.0.0.1,10.0.0.2"],"object":null}`; string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	b := NewColumnBuilder(schema.GroupResource{Resource: "services"}).AddArrayColumn(metav1.TableColumnDefinition{Name: "External IPs", Type: "integer"}, externalIPs)
+	if err := b.Err(); err == nil {
+		t.Error("expected an integer column of multiple values to be rejected")
+	}
+}
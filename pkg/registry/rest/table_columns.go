@@ -0,0 +1,591 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ExtraColumn is a column appended after the columns of a table convertor.
+type ExtraColumn struct {
+	// Definition describes the column.
+	Definition metav1.TableColumnDefinition
+	// Extract computes the cell of the column for an object.
+	Extract func(runtime.Object) (interface{}, error)
+}
+
+// WithExtraColumns appends columns after the columns of the convertor. By default, a column whose
+// cell cannot be extracted renders an empty cell; see WithFailOnExtraColumnError.
+func WithExtraColumns(extras ...ExtraColumn) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		for _, extra := range extras {
+			extract := extra.Extract
+			c.extraColumns = append(c.extraColumns, column{
+				definition: extra.Definition,
+				cell: func(_ context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+					value, err := extract(obj)
+					if err != nil {
+						if c.failOnExtraColumnError {
+							return nil, err
+						}
+						return "", nil
+					}
+					return value, nil
+				},
+			})
+		}
+	}
+}
+
+// withContentColumns appends columns whose cells are read from the content of the objects, which
+// is converted once per row for all of them.
+func withContentColumns(columns ...column) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.extraColumns = append(c.extraColumns, columns...)
+	}
+}
+
+// WithFailOnExtraColumnError fails the conversion when the cell of an extra column cannot be
+// extracted, instead of rendering an empty cell.
+func WithFailOnExtraColumnError() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.failOnExtraColumnError = true
+	}
+}
+
+// NewDefaultTableConvertorWithExtraColumns creates a default convertor that renders extras after
+// the default columns.
+func NewDefaultTableConvertorWithExtraColumns(defaultQualifiedResource schema.GroupResource, extras []ExtraColumn) TableConvertor {
+	return NewDefaultTableConvertor(defaultQualifiedResource, WithExtraColumns(extras...))
+}
+
+// WithConditionStatusColumn appends a Status column rendering the status of the condition with the
+// given type from status.conditions: "True", "False" or "Unknown". Objects without conditions, or
+// without a condition of that type, render an empty cell.
+func WithConditionStatusColumn(conditionType string) TableConvertorOption {
+	return withContentColumns(column{
+		definition: metav1.TableColumnDefinition{Name: "Status", Type: "string", Description: fmt.Sprintf("The status of the %s condition.", conditionType)},
+		cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+			return conditionStatus(ctx, obj, conditionType), nil
+		},
+	})
+}
+
+// conditionStatus returns the status of the condition of obj with the given type, or an empty
+// string if obj has no such condition.
+func conditionStatus(ctx context.Context, obj runtime.Object, conditionType string) string {
+	content, err := rowContent(ctx, obj)
+	if err != nil {
+		return ""
+	}
+	field, found, err := unstructured.NestedFieldNoCopy(content, "status", "conditions")
+	if !found || err != nil {
+		return ""
+	}
+	conditions, ok := field.([]interface{})
+	if !ok {
+		return ""
+	}
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		switch status := condition["status"]; status {
+		case string(metav1.ConditionTrue), string(metav1.ConditionFalse):
+			return status.(string)
+		default:
+			return string(metav1.ConditionUnknown)
+		}
+	}
+	return ""
+}
+
+// WithConditionsSummaryColumn appends a Conditions column summarizing the conditions of objects
+// from status.conditions as type=status pairs ordered by type, e.g.
+// "Available=True,Progressing=False". Statuses other than "True" and "False" are shown as
+// "Unknown", conditions without a type are skipped, and objects without conditions render an empty
+// cell.
+func WithConditionsSummaryColumn() TableConvertorOption {
+	return withContentColumns(column{
+		definition: metav1.TableColumnDefinition{Name: "Conditions", Type: "string", Description: "The type and status of the conditions of the object."},
+		cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+			return conditionsSummary(ctx, obj), nil
+		},
+	})
+}
+
+// conditionsSummary returns the type=status pairs of the conditions of obj, ordered by type.
+func conditionsSummary(ctx context.Context, obj runtime.Object) string {
+	content, err := rowContent(ctx, obj)
+	if err != nil {
+		return ""
+	}
+	field, found, err := unstructured.NestedFieldNoCopy(content, "status", "conditions")
+	if !found || err != nil {
+		return ""
+	}
+	conditions, ok := field.([]interface{})
+	if !ok {
+		return ""
+	}
+	pairs := make([]string, 0, len(conditions))
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, ok := condition["type"].(string)
+		if !ok || len(conditionType) == 0 {
+			continue
+		}
+		status := string(metav1.ConditionUnknown)
+		if s, ok := condition["status"].(string); ok && (s == string(metav1.ConditionTrue) || s == string(metav1.ConditionFalse)) {
+			status = s
+		}
+		pairs = append(pairs, conditionType+"="+status)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// WithStatusMessageColumn appends Reason and Message columns rendering status.reason and
+// status.message, with priority 1, for resources explaining their state, like failed objects.
+// Messages longer than maxWidth characters are truncated to maxWidth characters ending with an
+// ellipsis; they are never truncated if maxWidth is not positive. Objects without the fields
+// render empty cells.
+func WithStatusMessageColumn(maxWidth int) TableConvertorOption {
+	return withContentColumns(
+		column{
+			definition: metav1.TableColumnDefinition{Name: "Reason", Type: "string", Priority: 1, Description: "A brief CamelCase reason for the state of the object."},
+			cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+				return statusString(ctx, obj, "reason"), nil
+			},
+		},
+		column{
+			definition: metav1.TableColumnDefinition{Name: "Message", Type: "string", Priority: 1, Description: "A human readable message about the state of the object."},
+			cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+				return truncateString(statusString(ctx, obj, "message"), maxWidth), nil
+			},
+		},
+	)
+}
+
+// statusString returns the string field of the status of obj, or "" if it is absent.
+func statusString(ctx context.Context, obj runtime.Object, field string) string {
+	content, err := rowContent(ctx, obj)
+	if err != nil {
+		return ""
+	}
+	value, _, _ := unstructured.NestedString(content, "status", field)
+	return value
+}
+
+// truncateString returns s truncated to width characters ending with an ellipsis, if it is longer
+// and width is positive.
+func truncateString(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// WithPhaseColumn appends a Phase column rendering status.phase, like the status column of pods,
+// or an empty cell for objects without a phase. The rows of the objects in one of the given
+// terminal phases, for instance "Succeeded" and "Failed", get a metav1.RowCompleted condition
+// with the phase as reason, after the conditions of WithRowConditions, so that clients can
+// de-emphasize them.
+func WithPhaseColumn(terminalPhases ...string) TableConvertorOption {
+	phaseColumn := withContentColumns(column{
+		definition: metav1.TableColumnDefinition{Name: "Phase", Type: "string", Description: "The current phase of the object."},
+		cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+			return statusString(ctx, obj, "phase"), nil
+		},
+	})
+	return func(c *defaultTableConvertor) {
+		phaseColumn(c)
+		if len(terminalPhases) > 0 {
+			c.terminalPhases = sets.NewString(terminalPhases...)
+		}
+	}
+}
+
+// completedCondition returns the condition of the rows of objects in the terminal phase.
+func completedCondition(phase string) metav1.TableRowCondition {
+	return metav1.TableRowCondition{
+		Type:    metav1.RowCompleted,
+		Status:  metav1.ConditionTrue,
+		Reason:  phase,
+		Message: fmt.Sprintf("The object has %s", phase),
+	}
+}
+
+// RowWarning is the type of the condition of the rows that WithTypeColumn highlights.
+const RowWarning metav1.RowConditionType = "Warning"
+
+// typeColumnName is the name of the column of WithTypeColumn.
+const typeColumnName = "Type"
+
+// WithTypeColumn appends a Type column rendering the type or severity field at jsonPath, e.g.
+// ".type" for events, or an empty cell for objects without it. The rows of the objects of one of
+// the given warning types, for instance "Warning", get a RowWarning condition with the type as
+// reason, after the conditions of WithRowConditions and WithPhaseColumn, so that clients can
+// highlight them. Rows get no warning condition when the Type column is redacted by WithRedaction,
+// so that the condition does not reveal the type. A malformed jsonPath fails every conversion.
+func WithTypeColumn(jsonPath string, warningTypes ...string) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		typeColumn, err := jsonPathColumn(PrinterColumn{Name: typeColumnName, Type: "string", Description: "The type of the object.", JSONPath: jsonPath})
+		if err != nil {
+			c.failColumns(err)
+			return
+		}
+		cell := typeColumn.cell
+		typeColumn.cell = func(ctx context.Context, obj runtime.Object, m metav1.Object) (interface{}, error) {
+			value, err := cell(ctx, obj, m)
+			if value == nil && err == nil {
+				return "", nil
+			}
+			return value, err
+		}
+		if len(warningTypes) > 0 {
+			c.warningTypes = sets.NewString(warningTypes...)
+			c.typeCell = typeColumn.cell
+			typeColumn.warningType = true
+		}
+		c.extraColumns = append(c.extraColumns, typeColumn)
+	}
+}
+
+// warningCondition returns the condition of the rows of objects of a warning type.
+func warningCondition(t string) metav1.TableRowCondition {
+	return metav1.TableRowCondition{
+		Type:    RowWarning,
+		Status:  metav1.ConditionTrue,
+		Reason:  t,
+		Message: fmt.Sprintf("The object has the %s type", t),
+	}
+}
+
+// WithReadyReplicasColumn appends a Ready column rendering the ready and desired replicas of
+// workload-like objects from status.readyReplicas and spec.replicas, e.g. "3/5". A missing
+// status.readyReplicas counts as zero, since it is omitted when no replica is ready; objects
+// without spec.replicas render an empty cell.
+func WithReadyReplicasColumn() TableConvertorOption {
+	return withContentColumns(column{
+		definition: metav1.TableColumnDefinition{Name: "Ready", Type: "string", Description: "The number of ready replicas out of the desired replicas."},
+		cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+			return readyReplicas(ctx, obj), nil
+		},
+	})
+}
+
+// readyReplicas returns the ready and desired replicas of obj, or an empty string if obj has no
+// desired replicas.
+func readyReplicas(ctx context.Context, obj runtime.Object) string {
+	content, err := rowContent(ctx, obj)
+	if err != nil {
+		return ""
+	}
+	desired, ok := nestedInt64(content, "spec", "replicas")
+	if !ok {
+		return ""
+	}
+	ready, _ := nestedInt64(content, "status", "readyReplicas")
+	return fmt.Sprintf("%d/%d", ready, desired)
+}
+
+// nestedInt64 returns the integer at the path of fields in content, which may be decoded as any Go
+// integer or as an integral float.
+func nestedInt64(content map[string]interface{}, fields ...string) (int64, bool) {
+	value, found, err := unstructured.NestedFieldNoCopy(content, fields...)
+	if !found || err != nil {
+		return 0, false
+	}
+	if i, ok := toInt64(value); ok {
+		return i, true
+	}
+	if f, ok := toFloat64(value); ok && f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+		return int64(f), true
+	}
+	return 0, false
+}
+
+// WithControllerColumn appends a Controller column rendering the kind and name of the controlling
+// owner of objects, e.g. "ReplicaSet/web-5d4f8", from the owner reference with controller set to
+// true. Objects without a controlling owner render an empty cell.
+func WithControllerColumn() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.extraColumns = append(c.extraColumns, column{
+			definition: metav1.TableColumnDefinition{Name: "Controller", Type: "string", Description: "The kind and name of the controlling owner of the object."},
+			cell: func(_ context.Context, _ runtime.Object, m metav1.Object) (interface{}, error) {
+				owner := metav1.GetControllerOfNoCopy(m)
+				if owner == nil {
+					return "", nil
+				}
+				return owner.Kind + "/" + owner.Name, nil
+			},
+		})
+	}
+}
+
+// WithUIDColumn appends a UID column rendering the UID of objects, to tell apart objects that were
+// deleted and recreated with the same name. The column has priority 1, so it is only shown in
+// wide output.
+func WithUIDColumn() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.extraColumns = append(c.extraColumns, column{
+			definition: metav1.TableColumnDefinition{Name: "UID", Type: "string", Priority: 1, Description: metadataDescriptions()["uid"]},
+			cell: func(_ context.Context, _ runtime.Object, m metav1.Object) (interface{}, error) {
+				return string(m.GetUID()), nil
+			},
+		})
+	}
+}
+
+// WithKindColumn renders the kind of objects as the first column, to tell apart the rows of lists
+// mixing several kinds, like the output of "kubectl get all". The kind is read from the type
+// metadata of objects or, for objects without type metadata such as the items of typed lists, from
+// the scheme set with WithScheme. Objects of an unknown kind render an empty cell. The column is
+// not rendered by ConvertToTableWithColumns.
+func WithKindColumn() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.kindColumn = true
+	}
+}
+
+// newKindColumn returns the column of WithKindColumn.
+func (c *defaultTableConvertor) newKindColumn() column {
+	return column{
+		definition: metav1.TableColumnDefinition{Name: "Kind", Type: "string", Description: "The kind of the object."},
+		cell: func(_ context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+			if kind := obj.GetObjectKind().GroupVersionKind().Kind; len(kind) > 0 {
+				return kind, nil
+			}
+			if c.scheme != nil {
+				if gvks, _, err := c.scheme.ObjectKinds(obj); err == nil {
+					return gvks[0].Kind, nil
+				}
+			}
+			return "", nil
+		},
+	}
+}
+
+// WithResourceVersionColumn appends a Resource Version column rendering the resource version of
+// objects, for tools that update objects with optimistic concurrency from table output. The column
+// has priority 1, so it is only shown in wide output.
+func WithResourceVersionColumn() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.extraColumns = append(c.extraColumns, column{
+			definition: metav1.TableColumnDefinition{Name: "Resource Version", Type: "string", Priority: 1, Description: metadataDescriptions()["resourceVersion"]},
+			cell: func(_ context.Context, _ runtime.Object, m metav1.Object) (interface{}, error) {
+				return m.GetResourceVersion(), nil
+			},
+		})
+	}
+}
+
+// WithConstantColumn appends a column rendering value in every row, for instance the name of the
+// cluster the objects come from when tables of several clusters are aggregated. value is
+// normalized with FormatCell once and shared by the rows, so it must not be modified afterwards.
+func WithConstantColumn(def metav1.TableColumnDefinition, value interface{}) TableConvertorOption {
+	cell := FormatCell(def.Type, def.Format, value)
+	return func(c *defaultTableConvertor) {
+		c.extraColumns = append(c.extraColumns, column{
+			definition: def,
+			cell: func(context.Context, runtime.Object, metav1.Object) (interface{}, error) {
+				return cell, nil
+			},
+		})
+	}
+}
+
+// WithFieldSelectorColumns appends a column per field referenced by selector, in the order of its
+// requirements, such as "spec.nodeName" for the selector "spec.nodeName=node1", named after the
+// field, so that readers can see why objects matched the selector. Scalar fields render as the
+// text they are compared to by field selectors, e.g. "true" for booleans, while missing fields and
+// fields holding objects or arrays render empty cells. Since options cannot fail, a field that is
+// not a path of field names makes every conversion fail.
+func WithFieldSelectorColumns(selector fields.Selector) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		if selector == nil {
+			return
+		}
+		seen := sets.NewString()
+		for _, requirement := range selector.Requirements() {
+			field := requirement.Field
+			if seen.Has(field) {
+				continue
+			}
+			seen.Insert(field)
+			path, err := fieldPath(field)
+			if err != nil {
+				c.failColumns(fmt.Errorf("unrecognized field %q of the field selector: %v", field, err))
+				return
+			}
+			c.extraColumns = append(c.extraColumns, column{
+				definition: metav1.TableColumnDefinition{Name: field, Type: "string", Description: fmt.Sprintf("The %s field of the field selector.", field)},
+				jsonPath:   "." + field,
+				cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+					content, err := rowContent(ctx, obj)
+					if err != nil {
+						return nil, err
+					}
+					value, found, err := unstructured.NestedFieldNoCopy(content, path...)
+					if err != nil || !found {
+						return nil, nil
+					}
+					switch value.(type) {
+					case map[string]interface{}, []interface{}, nil:
+						return nil, nil
+					}
+					return fmt.Sprint(value), nil
+				},
+			})
+		}
+	}
+}
+
+// WithTimestampColumn appends a column with the given name rendering the timestamp at the
+// JSONPath of objects, such as ".status.startTime", like the creation column: as an RFC3339 date,
+// or as the time elapsed since the timestamp if WithAgeColumn or WithAgeFormat is set. Missing
+// fields and values that are not RFC3339 timestamps render an empty cell, or "<unknown>" for ages.
+// Since options cannot fail, a malformed JSONPath makes every conversion fail.
+func WithTimestampColumn(name string, jsonPath string) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		extract, err := jsonPathColumn(PrinterColumn{Name: name, JSONPath: jsonPath})
+		if err != nil {
+			c.failColumns(err)
+			return
+		}
+		c.extraColumns = append(c.extraColumns, column{
+			definition: metav1.TableColumnDefinition{Name: name, Type: "date", Description: fmt.Sprintf("The timestamp at %s.", jsonPath)},
+			cell: func(ctx context.Context, obj runtime.Object, m metav1.Object) (interface{}, error) {
+				value, err := extract.cell(ctx, obj, m)
+				if err != nil {
+					return nil, err
+				}
+				return c.timestampCell(parseTimestamp(value)), nil
+			},
+			jsonPath:  jsonPath,
+			timestamp: true,
+		})
+	}
+}
+
+// WithDurationSinceColumn appends a column with the given name rendering the time elapsed since the
+// timestamp at the JSONPath of objects, for instance "Running For" for ".status.startTime", in the
+// format set with WithAgeFormat, AgeLong by default. Unlike WithTimestampColumn, the column renders
+// durations whether or not ages are shown, and missing fields and values that are not RFC3339
+// timestamps render an empty cell. A malformed JSONPath makes every conversion fail.
+func WithDurationSinceColumn(name, jsonPath string) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		extract, err := jsonPathColumn(PrinterColumn{Name: name, JSONPath: jsonPath})
+		if err != nil {
+			c.failColumns(err)
+			return
+		}
+		c.extraColumns = append(c.extraColumns, column{
+			definition: metav1.TableColumnDefinition{Name: name, Type: "string", Description: fmt.Sprintf("The time elapsed since the timestamp at %s.", jsonPath)},
+			cell: func(ctx context.Context, obj runtime.Object, m metav1.Object) (interface{}, error) {
+				value, err := extract.cell(ctx, obj, m)
+				if err != nil {
+					return nil, err
+				}
+				since := parseTimestamp(value)
+				if since.IsZero() {
+					return "", nil
+				}
+				if c.ageFormat == nil {
+					return AgeLong(c.clock.Since(since.Time)), nil
+				}
+				return c.ageFormat(c.clock.Since(since.Time)), nil
+			},
+			jsonPath: jsonPath,
+		})
+	}
+}
+
+// parseTimestamp returns the timestamp held by value, or the zero time if value is not a
+// timestamp.
+func parseTimestamp(value interface{}) metav1.Time {
+	switch t := value.(type) {
+	case string:
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return metav1.Time{}
+		}
+		return metav1.NewTime(parsed)
+	case time.Time:
+		return metav1.NewTime(t)
+	case metav1.Time:
+		return t
+	}
+	return metav1.Time{}
+}
+
+// WithColumnPlacement renders the printer columns of before ahead of the other columns of the
+// convertor, and the printer columns of after right behind them, ahead of the extra columns. Since
+// options cannot fail, a malformed JSONPath, or a column name shared by several of the columns
+// rendered by the convertor, including its default and extra columns, makes every conversion
+// fail.
+func WithColumnPlacement(before, after []PrinterColumn) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		for _, placed := range []struct {
+			printerColumns []PrinterColumn
+			columns        *[]column
+		}{{before, &c.columnsBefore}, {after, &c.columnsAfter}} {
+			for _, col := range placed.printerColumns {
+				tableColumn, err := jsonPathColumn(col)
+				if err != nil {
+					c.failColumns(err)
+					return
+				}
+				*placed.columns = append(*placed.columns, tableColumn)
+			}
+		}
+	}
+}
+
+// duplicateColumnNames returns the sorted names shared by several of columns.
+func duplicateColumnNames(columns []column) []string {
+	seen := make(map[string]bool, len(columns))
+	var duplicates []string
+	for _, col := range columns {
+		name := col.definition.Name
+		if seen[name] {
+			duplicates = append(duplicates, name)
+		}
+		seen[name] = true
+	}
+	sort.Strings(duplicates)
+	return duplicates
+}
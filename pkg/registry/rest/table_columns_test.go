@@ -0,0 +1,699 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
+	"k8s.io/apiserver/pkg/apis/example/install"
+	examplev1 "k8s.io/apiserver/pkg/apis/example/v1"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestDefaultTableConvertorWithExtraColumns(t *testing.T) {
+	list := &example.PodList{Items: []example.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Spec: example.PodSpec{NodeName: "node1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: example.PodSpec{NodeName: "node2"}},
+	}}
+	failing := func(obj runtime.Object) (interface{}, error) {
+		if obj.(*example.Pod).Name == "b" {
+			return nil, fmt.Errorf("cannot extract")
+		}
+		return podNodeName(obj)
+	}
+	extras := []ExtraColumn{
+		{Definition: metav1.TableColumnDefinition{Name: "Node", Type: "string"}, Extract: podNodeName},
+		{Definition: metav1.TableColumnDefinition{Name: "Failing", Type: "string"}, Extract: failing},
+	}
+
+	table, err := NewDefaultTableConvertorWithExtraColumns(schema.GroupResource{Resource: "pods"}, extras).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var columns []string
+	for _, def := range table.ColumnDefinitions {
+		columns = append(columns, def.Name)
+	}
+	if expected := []string{"Name", "Created At", "Node", "Failing"}; !reflect.DeepEqual(expected, columns) {
+		t.Errorf("expected columns %v, got %v", expected, columns)
+	}
+	expected := [][]interface{}{{"a", "node1", "node1"}, {"b", "node2", ""}}
+	for i, row := range table.Rows {
+		if len(row.Cells) != len(columns) {
+			t.Fatalf("row %d has %d cells for %d columns", i, len(row.Cells), len(columns))
+		}
+		if got := []interface{}{row.Cells[0], row.Cells[2], row.Cells[3]}; !reflect.DeepEqual(expected[i], got) {
+			t.Errorf("row %d: expected cells %v, got %v", i, expected[i], got)
+		}
+	}
+
+	_, err = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithExtraColumns(extras...), WithFailOnExtraColumnError()).ConvertToTable(context.TODO(), list, nil)
+	if err == nil {
+		t.Errorf("expected the extraction error to fail the conversion")
+	}
+}
+
+func TestWithConditionStatusColumn(t *testing.T) {
+	conditions := func(conditions ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"status": map[string]interface{}{"conditions": conditions}}
+	}
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("ready", conditions(
+			map[string]interface{}{"type": "Scheduled", "status": "False"},
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		))},
+		{Object: newUnstructured("not-ready", conditions(map[string]interface{}{"type": "Ready", "status": "False"}))},
+		{Object: newUnstructured("unknown", conditions(map[string]interface{}{"type": "Ready"}))},
+		{Object: newUnstructured("other", conditions(map[string]interface{}{"type": "Scheduled", "status": "True"}))},
+		{Object: newUnstructured("none", nil)},
+		{Object: newUnstructured("malformed", map[string]interface{}{"status": "Running"})},
+		{Object: &examplev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "typed"},
+			Status:     examplev1.PodStatus{Conditions: []examplev1.PodCondition{{Type: "Ready", Status: "True"}}},
+		}},
+	}}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "widgets"}, WithConditionStatusColumn("Ready"))
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def := table.ColumnDefinitions[len(table.ColumnDefinitions)-1]; def.Name != "Status" || def.Type != "string" {
+		t.Errorf("unexpected column: %#v", def)
+	}
+	var statuses []interface{}
+	for _, row := range table.Rows {
+		statuses = append(statuses, row.Cells[len(row.Cells)-1])
+	}
+	if expected := []interface{}{"True", "False", "Unknown", "", "", "", "True"}; !reflect.DeepEqual(expected, statuses) {
+		t.Errorf("expected statuses %v, got %v", expected, statuses)
+	}
+}
+
+func TestWithConditionsSummaryColumn(t *testing.T) {
+	conditions := func(conditions ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"status": map[string]interface{}{"conditions": conditions}}
+	}
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("healthy", conditions(
+			map[string]interface{}{"type": "Progressing", "status": "False"},
+			map[string]interface{}{"type": "Available", "status": "True"},
+			map[string]interface{}{"type": "Degraded"},
+		))},
+		{Object: newUnstructured("malformed", conditions("Ready", map[string]interface{}{"status": "True"}, map[string]interface{}{"type": "Ready", "status": "True"}))},
+		{Object: newUnstructured("empty", conditions())},
+		{Object: newUnstructured("none", nil)},
+		{Object: &examplev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "typed"},
+			Status:     examplev1.PodStatus{Conditions: []examplev1.PodCondition{{Type: "Ready", Status: "True"}, {Type: "Initialized", Status: "True"}}},
+		}},
+	}}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "widgets"}, WithConditionsSummaryColumn())
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def := table.ColumnDefinitions[len(table.ColumnDefinitions)-1]; def.Name != "Conditions" || def.Type != "string" {
+		t.Errorf("unexpected column: %#v", def)
+	}
+	var summaries []interface{}
+	for _, row := range table.Rows {
+		summaries = append(summaries, row.Cells[len(row.Cells)-1])
+	}
+	if expected := []interface{}{"Available=True,Degraded=Unknown,Progressing=False", "Ready=True", "", "", "Initialized=True,Ready=True"}; !reflect.DeepEqual(expected, summaries) {
+		t.Errorf("expected summaries %v, got %v", expected, summaries)
+	}
+}
+
+func TestWithStatusMessageColumn(t *testing.T) {
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("failed", map[string]interface{}{"status": map[string]interface{}{"reason": "Evicted", "message": "The node was low on resource: memory."}})},
+		{Object: newUnstructured("short", map[string]interface{}{"status": map[string]interface{}{"message": "café crème"}})},
+		{Object: newUnstructured("none", nil)},
+		{Object: &examplev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "typed"},
+			Status:     examplev1.PodStatus{Reason: "NodeLost", Message: "Node is unreachable"},
+		}},
+	}}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithStatusMessageColumn(10))
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"Reason", "Message"} {
+		if def := table.ColumnDefinitions[2+i]; def.Name != name || def.Type != "string" || def.Priority != 1 {
+			t.Errorf("unexpected column: %#v", def)
+		}
+	}
+	var cells [][]interface{}
+	for _, row := range table.Rows {
+		cells = append(cells, row.Cells[2:])
+	}
+	expected := [][]interface{}{
+		{"Evicted", "The node …"},
+		{"", "café crème"},
+		{"", ""},
+		{"NodeLost", "Node is u…"},
+	}
+	if !reflect.DeepEqual(expected, cells) {
+		t.Errorf("expected cells %q, got %q", expected, cells)
+	}
+
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithStatusMessageColumn(0))
+	if table, err = c.ConvertToTable(context.TODO(), list, nil); err != nil {
+		t.Fatal(err)
+	}
+	if message := table.Rows[0].Cells[3]; message != "The node was low on resource: memory." {
+		t.Errorf("expected the message not to be truncated, got %q", message)
+	}
+}
+
+func TestWithPhaseColumn(t *testing.T) {
+	phase := func(phase string) map[string]interface{} {
+		return map[string]interface{}{"status": map[string]interface{}{"phase": phase}}
+	}
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("running", phase("Running"))},
+		{Object: newUnstructured("failed", phase("Failed"))},
+		{Object: newUnstructured("none", nil)},
+		{Object: &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "typed"}, Status: examplev1.PodStatus{Phase: "Succeeded"}}},
+	}}
+	failing := func(runtime.Object) []metav1.TableRowCondition {
+		return []metav1.TableRowCondition{{Type: RowConversionFailed, Status: metav1.ConditionFalse}}
+	}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithPhaseColumn("Succeeded", "Failed"), WithRowConditions(failing))
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def := table.ColumnDefinitions[2]; def.Name != "Phase" || def.Type != "string" {
+		t.Errorf("unexpected column: %#v", def)
+	}
+	for i, expected := range []struct {
+		phase     string
+		completed bool
+	}{{"Running", false}, {"Failed", true}, {"", false}, {"Succeeded", true}} {
+		row := table.Rows[i]
+		if row.Cells[2] != expected.phase {
+			t.Errorf("row %d: expected phase %q, got %#v", i, expected.phase, row.Cells[2])
+		}
+		conditions := []metav1.TableRowCondition{{Type: RowConversionFailed, Status: metav1.ConditionFalse}}
+		if expected.completed {
+			conditions = append(conditions, metav1.TableRowCondition{Type: metav1.RowCompleted, Status: metav1.ConditionTrue, Reason: expected.phase, Message: "The object has " + expected.phase})
+		}
+		if !reflect.DeepEqual(conditions, row.Conditions) {
+			t.Errorf("row %d: expected conditions %#v, got %#v", i, conditions, row.Conditions)
+		}
+	}
+
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithPhaseColumn())
+	if table, err = c.ConvertToTable(context.TODO(), list, nil); err != nil {
+		t.Fatal(err)
+	}
+	if conditions := table.Rows[1].Conditions; len(conditions) != 0 {
+		t.Errorf("expected no conditions without terminal phases, got %#v", conditions)
+	}
+}
+
+func TestWithTypeColumn(t *testing.T) {
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("normal", map[string]interface{}{"type": "Normal"})},
+		{Object: newUnstructured("warning", map[string]interface{}{"type": "Warning"})},
+		{Object: newUnstructured("none", nil)},
+	}}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "events"}, WithTypeColumn(".type", "Warning"))
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def := table.ColumnDefinitions[2]; def.Name != "Type" || def.Type != "string" {
+		t.Errorf("unexpected column: %#v", def)
+	}
+	for i, expected := range []struct {
+		eventType string
+		warning   bool
+	}{{"Normal", false}, {"Warning", true}, {"", false}} {
+		row := table.Rows[i]
+		if row.Cells[2] != expected.eventType {
+			t.Errorf("row %d: expected type %q, got %#v", i, expected.eventType, row.Cells[2])
+		}
+		var conditions []metav1.TableRowCondition
+		if expected.warning {
+			conditions = []metav1.TableRowCondition{{Type: RowWarning, Status: metav1.ConditionTrue, Reason: "Warning", Message: "The object has the Warning type"}}
+		}
+		if !reflect.DeepEqual(conditions, row.Conditions) {
+			t.Errorf("row %d: expected conditions %#v, got %#v", i, conditions, row.Conditions)
+		}
+	}
+
+	// the type cells of the rendered column are evaluated once per row
+	counted := NewDefaultTableConvertor(schema.GroupResource{Resource: "events"}, WithTypeColumn(".type", "Warning")).(*defaultTableConvertor)
+	evaluations := 0
+	count := func(cell func(context.Context, runtime.Object, metav1.Object) (interface{}, error)) func(context.Context, runtime.Object, metav1.Object) (interface{}, error) {
+		return func(ctx context.Context, obj runtime.Object, m metav1.Object) (interface{}, error) {
+			evaluations++
+			return cell(ctx, obj, m)
+		}
+	}
+	counted.columns[2].cell = count(counted.columns[2].cell)
+	counted.typeCell = count(counted.typeCell)
+	if table, err = counted.ConvertToTable(context.TODO(), list, nil); err != nil {
+		t.Fatal(err)
+	}
+	if evaluations != len(list.Items) || len(table.Rows[1].Conditions) != 1 {
+		t.Errorf("expected a warning from %d type cells, got %d evaluations and conditions %#v", len(list.Items), evaluations, table.Rows[1].Conditions)
+	}
+
+	// rows warn without rendering the type column
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "events"}, WithTypeColumn(".type", "Warning"), WithIncludeColumns("Name"))
+	if table, err = c.ConvertToTable(context.TODO(), list, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 1 || len(table.Rows[1].Conditions) != 1 || len(table.Rows[0].Conditions) != 0 {
+		t.Errorf("expected a warning for the row of the warning type only, got %#v", table.Rows)
+	}
+
+	// redacted types are not revealed by the conditions, whether the column is rendered or not
+	never := func(context.Context) bool { return false }
+	for name, opts := range map[string][]TableConvertorOption{
+		"rendered": {WithTypeColumn(".type", "Warning"), WithRedaction(never, "Type")},
+		"excluded": {WithTypeColumn(".type", "Warning"), WithRedaction(never, "Type"), WithIncludeColumns("Name")},
+	} {
+		c = NewDefaultTableConvertor(schema.GroupResource{Resource: "events"}, opts...)
+		if table, err = c.ConvertToTable(context.TODO(), list, nil); err != nil {
+			t.Fatal(err)
+		}
+		for i, row := range table.Rows {
+			if len(row.Conditions) != 0 {
+				t.Errorf("%s: expected no conditions for the redacted type of row %d, got %#v", name, i, row.Conditions)
+			}
+		}
+	}
+
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "events"}, WithTypeColumn(".type"))
+	if table, err = c.ConvertToTable(context.TODO(), list, nil); err != nil {
+		t.Fatal(err)
+	}
+	if conditions := table.Rows[1].Conditions; len(conditions) != 0 {
+		t.Errorf("expected no conditions without warning types, got %#v", conditions)
+	}
+
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "events"}, WithTypeColumn("{.type"))
+	if _, err := c.ConvertToTable(context.TODO(), list, nil); err == nil {
+		t.Error("expected an error for a malformed JSONPath")
+	}
+}
+
+func TestWithReadyReplicasColumn(t *testing.T) {
+	replicas := func(spec, status map[string]interface{}) map[string]interface{} {
+		fields := map[string]interface{}{}
+		if spec != nil {
+			fields["spec"] = spec
+		}
+		if status != nil {
+			fields["status"] = status
+		}
+		return fields
+	}
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("partial", replicas(map[string]interface{}{"replicas": int64(5)}, map[string]interface{}{"readyReplicas": int64(3)}))},
+		{Object: newUnstructured("decoded", replicas(map[string]interface{}{"replicas": float64(2)}, map[string]interface{}{"readyReplicas": float64(2)}))},
+		{Object: newUnstructured("none-ready", replicas(map[string]interface{}{"replicas": int64(1)}, nil))},
+		{Object: newUnstructured("no-spec", replicas(nil, map[string]interface{}{"readyReplicas": int64(1)}))},
+		{Object: newUnstructured("malformed", replicas(map[string]interface{}{"replicas": "five"}, nil))},
+		{Object: newUnstructured("empty", nil)},
+	}}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "deployments"}, WithReadyReplicasColumn())
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def := table.ColumnDefinitions[len(table.ColumnDefinitions)-1]; def.Name != "Ready" || def.Type != "string" {
+		t.Errorf("unexpected column: %#v", def)
+	}
+	var ready []interface{}
+	for _, row := range table.Rows {
+		ready = append(ready, row.Cells[len(row.Cells)-1])
+	}
+	if expected := []interface{}{"3/5", "2/2", "0/1", "", "", ""}; !reflect.DeepEqual(expected, ready) {
+		t.Errorf("expected %v, got %v", expected, ready)
+	}
+}
+
+func TestWithControllerColumn(t *testing.T) {
+	isController := true
+	notController := false
+	pod := func(name string, refs ...metav1.OwnerReference) example.Pod {
+		return example.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, OwnerReferences: refs}}
+	}
+	list := &example.PodList{Items: []example.Pod{
+		pod("owned",
+			metav1.OwnerReference{Kind: "Node", Name: "node1", Controller: &notController},
+			metav1.OwnerReference{Kind: "ReplicaSet", Name: "web-5d4f8", Controller: &isController},
+		),
+		pod("not-controlled", metav1.OwnerReference{Kind: "ConfigMap", Name: "config"}),
+		pod("orphan"),
+	}}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithControllerColumn())
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def := table.ColumnDefinitions[len(table.ColumnDefinitions)-1]; def.Name != "Controller" || def.Type != "string" {
+		t.Errorf("unexpected column: %#v", def)
+	}
+	var controllers []interface{}
+	for _, row := range table.Rows {
+		controllers = append(controllers, row.Cells[len(row.Cells)-1])
+	}
+	if expected := []interface{}{"ReplicaSet/web-5d4f8", "", ""}; !reflect.DeepEqual(expected, controllers) {
+		t.Errorf("expected %v, got %v", expected, controllers)
+	}
+}
+
+func TestWithMetadataColumns(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "3f2a9c1e-7d6b-4b8e-9a55-0c1d2e3f4a5b", ResourceVersion: "4217"}}
+
+	testCases := []struct {
+		name         string
+		option       TableConvertorOption
+		column       string
+		expectedCell string
+	}{
+		{name: "uid", option: WithUIDColumn(), column: "UID", expectedCell: "3f2a9c1e-7d6b-4b8e-9a55-0c1d2e3f4a5b"},
+		{name: "resource version", option: WithResourceVersionColumn(), column: "Resource Version", expectedCell: "4217"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), pod, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, def := range table.ColumnDefinitions {
+				if def.Name == tc.column {
+					t.Errorf("expected no %s column without the option, got %#v", tc.column, table.ColumnDefinitions)
+				}
+			}
+
+			table, err = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, tc.option).ConvertToTable(context.TODO(), pod, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := len(table.ColumnDefinitions) - 1
+			if def := table.ColumnDefinitions[last]; def.Name != tc.column || def.Type != "string" || def.Priority != 1 || len(def.Description) == 0 {
+				t.Errorf("unexpected column: %#v", def)
+			}
+			if cell := table.Rows[0].Cells[last]; cell != tc.expectedCell {
+				t.Errorf("expected %q, got %v", tc.expectedCell, cell)
+			}
+		})
+	}
+}
+
+func TestWithKindColumn(t *testing.T) {
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("widget", nil)},
+		{Object: &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod"}}},
+		{Object: &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "unknown"}}},
+	}}
+	scheme := runtime.NewScheme()
+	install.Install(scheme)
+
+	testCases := []struct {
+		name          string
+		options       []TableConvertorOption
+		expectedKinds []interface{}
+	}{
+		{name: "type metadata", options: []TableConvertorOption{WithKindColumn()}, expectedKinds: []interface{}{"Widget", "", ""}},
+		{name: "scheme", options: []TableConvertorOption{WithKindColumn(), WithScheme(scheme)}, expectedKinds: []interface{}{"Widget", "Pod", ""}},
+		{name: "namespace", options: []TableConvertorOption{WithScheme(scheme), WithNamespaceColumn(), WithKindColumn()}, expectedKinds: []interface{}{"Widget", "Pod", ""}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "all"}, tc.options...).ConvertToTable(context.TODO(), list, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if def := table.ColumnDefinitions[0]; def.Name != "Kind" || def.Type != "string" {
+				t.Errorf("unexpected first column: %#v", def)
+			}
+			var kinds []interface{}
+			for _, row := range table.Rows {
+				kinds = append(kinds, row.Cells[0])
+			}
+			if !reflect.DeepEqual(tc.expectedKinds, kinds) {
+				t.Errorf("expected kinds %v, got %v", tc.expectedKinds, kinds)
+			}
+		})
+	}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "all"}, WithKindColumn()).(TableColumnsConvertor)
+	table, err := c.ConvertToTableWithColumns(context.TODO(), list, nil, []PrinterColumn{{Name: "Name", Type: "string", JSONPath: ".metadata.name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 1 || table.ColumnDefinitions[0].Name != "Name" {
+		t.Errorf("expected only the requested columns, got %#v", table.ColumnDefinitions)
+	}
+}
+
+func TestWithTimestampColumn(t *testing.T) {
+	started := metav1.NewTime(testNow.Add(-2 * time.Hour))
+	status := func(startTime interface{}) map[string]interface{} {
+		return map[string]interface{}{"status": map[string]interface{}{"startTime": startTime}}
+	}
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("started", status("2021-06-01T10:00:00Z"))},
+		{Object: newUnstructured("malformed", status("yesterday"))},
+		{Object: newUnstructured("number", status(int64(1622541600)))},
+		{Object: newUnstructured("pending", nil)},
+		{Object: &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "typed"}, Status: examplev1.PodStatus{StartTime: &started}}},
+	}}
+	testCases := []struct {
+		name          string
+		options       []TableConvertorOption
+		expectedType  string
+		expectedCells []interface{}
+	}{
+		{name: "date", expectedType: "date", expectedCells: []interface{}{"2021-06-01T10:00:00Z", "", "", "", "2021-06-01T10:00:00Z"}},
+		{name: "age", options: []TableConvertorOption{WithAgeFormat(AgeShort)}, expectedType: "string", expectedCells: []interface{}{"2h", "<unknown>", "<unknown>", "<unknown>", "2h"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := append([]TableConvertorOption{WithTimestampColumn("Started", ".status.startTime"), WithClock(testingclock.NewFakeClock(testNow))}, tc.options...)
+			table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, options...).ConvertToTable(context.TODO(), list, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if def := table.ColumnDefinitions[len(table.ColumnDefinitions)-1]; def.Name != "Started" || def.Type != tc.expectedType {
+				t.Errorf("unexpected column: %#v", def)
+			}
+			var cells []interface{}
+			for _, row := range table.Rows {
+				cells = append(cells, row.Cells[len(row.Cells)-1])
+			}
+			if !reflect.DeepEqual(tc.expectedCells, cells) {
+				t.Errorf("expected cells %v, got %v", tc.expectedCells, cells)
+			}
+		})
+	}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithTimestampColumn("Started", ".status[startTime"))
+	if _, err := c.ConvertToTable(context.TODO(), list, nil); err == nil {
+		t.Errorf("expected an error for a malformed JSONPath")
+	}
+}
+
+func TestWithDurationSinceColumn(t *testing.T) {
+	started := metav1.NewTime(testNow.Add(-2 * time.Hour))
+	status := func(startTime interface{}) map[string]interface{} {
+		return map[string]interface{}{"status": map[string]interface{}{"startTime": startTime}}
+	}
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("started", status("2021-06-01T10:00:00Z"))},
+		{Object: newUnstructured("malformed", status("yesterday"))},
+		{Object: newUnstructured("pending", nil)},
+		{Object: &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "typed"}, Status: examplev1.PodStatus{StartTime: &started}}},
+	}}
+	testCases := []struct {
+		name          string
+		options       []TableConvertorOption
+		expectedCells []interface{}
+	}{
+		{name: "default", expectedCells: []interface{}{AgeLong(2 * time.Hour), "", "", AgeLong(2 * time.Hour)}},
+		{name: "short", options: []TableConvertorOption{WithAgeFormat(AgeShort)}, expectedCells: []interface{}{"2h", "", "", "2h"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := append([]TableConvertorOption{WithDurationSinceColumn("Running For", ".status.startTime"), WithClock(testingclock.NewFakeClock(testNow))}, tc.options...)
+			table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, options...).ConvertToTable(context.TODO(), list, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if def := table.ColumnDefinitions[len(table.ColumnDefinitions)-1]; def.Name != "Running For" || def.Type != "string" {
+				t.Errorf("unexpected column: %#v", def)
+			}
+			var cells []interface{}
+			for _, row := range table.Rows {
+				cells = append(cells, row.Cells[len(row.Cells)-1])
+			}
+			if !reflect.DeepEqual(tc.expectedCells, cells) {
+				t.Errorf("expected cells %v, got %v", tc.expectedCells, cells)
+			}
+		})
+	}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithDurationSinceColumn("Running For", ".status[startTime"))
+	if _, err := c.ConvertToTable(context.TODO(), list, nil); err == nil {
+		t.Errorf("expected an error for a malformed JSONPath")
+	}
+	// the error of the first failing option is reported
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithDurationSinceColumn("Running For", ".status[startTime"), WithTimestampColumn("Started", ".status.startTime"), WithTypeColumn("{.type"))
+	if _, err := c.ConvertToTable(context.TODO(), list, nil); err == nil || !strings.Contains(err.Error(), ".status[startTime") {
+		t.Errorf("expected the error of the malformed JSONPath, got %v", err)
+	}
+}
+
+func TestWithColumnPlacement(t *testing.T) {
+	obj := newUnstructured("foo", map[string]interface{}{
+		"spec":   map[string]interface{}{"replicas": int64(3)},
+		"status": map[string]interface{}{"phase": "Running"},
+	})
+	before := []PrinterColumn{{Name: "Phase", Type: "string", JSONPath: ".status.phase"}}
+	after := []PrinterColumn{{Name: "Replicas", Type: "integer", JSONPath: ".spec.replicas"}}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "widgets"}, WithColumnPlacement(before, after), WithUIDColumn())
+	table, err := c.ConvertToTable(context.TODO(), obj, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, def := range table.ColumnDefinitions {
+		names = append(names, def.Name)
+	}
+	if expected := []string{"Phase", "Name", "Created At", "Replicas", "UID"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected columns %q, got %q", expected, names)
+	}
+	if cells := table.Rows[0].Cells; cells[0] != "Running" || cells[1] != "foo" || cells[3] != int64(3) {
+		t.Errorf("unexpected cells %#v", cells)
+	}
+
+	tests := map[string][]TableConvertorOption{
+		"duplicate placed column":  {WithColumnPlacement(before, before)},
+		"duplicate default column": {WithColumnPlacement([]PrinterColumn{{Name: "Name", JSONPath: ".metadata.name"}}, nil)},
+		"duplicate extra column":   {WithColumnPlacement(nil, []PrinterColumn{{Name: "UID", JSONPath: ".metadata.uid"}}), WithUIDColumn()},
+		"malformed JSONPath":       {WithColumnPlacement(nil, []PrinterColumn{{Name: "Bad", JSONPath: ".spec[[bad"}})},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := NewDefaultTableConvertor(schema.GroupResource{Resource: "widgets"}, opts...)
+			if _, err := c.ConvertToTable(context.TODO(), obj, nil); err == nil {
+				t.Errorf("expected the conversion to fail")
+			}
+		})
+	}
+}
+
+func TestWithConstantColumn(t *testing.T) {
+	list := &examplev1.PodList{Items: []examplev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "bar"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "baz"}},
+	}}
+	def := metav1.TableColumnDefinition{Name: "Cluster", Type: "string", Description: "The cluster of the object."}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithConstantColumn(def, "east-1"))
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := table.ColumnDefinitions[2]; got != def {
+		t.Errorf("expected column %#v, got %#v", def, got)
+	}
+	for i, row := range table.Rows {
+		if row.Cells[2] != "east-1" {
+			t.Errorf("row %d: expected the constant cell, got %#v", i, row.Cells)
+		}
+	}
+
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithConstantColumn(metav1.TableColumnDefinition{Name: "Shard", Type: "integer"}, int32(2)))
+	if table, err = c.ConvertToTable(context.TODO(), list, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cell := table.Rows[0].Cells[2]; cell != int64(2) {
+		t.Errorf("expected the constant to be normalized for its column type, got %#v", cell)
+	}
+}
+
+func TestWithFieldSelectorColumns(t *testing.T) {
+	selector, err := fields.ParseSelector("spec.nodeName=node1,status.phase!=Failed,spec.nodeName!=node2,spec.unschedulable=true,spec.replicas=3,spec.template=x,spec.missing=y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := newUnstructured("foo", map[string]interface{}{
+		"spec": map[string]interface{}{
+			"nodeName":      "node1",
+			"unschedulable": true,
+			"replicas":      int64(3),
+			"template":      map[string]interface{}{"metadata": map[string]interface{}{}},
+		},
+		"status": map[string]interface{}{"phase": "Running"},
+	})
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithFieldSelectorColumns(selector))
+	table, err := c.ConvertToTable(context.TODO(), obj, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, def := range table.ColumnDefinitions[2:] {
+		names = append(names, def.Name)
+	}
+	if expected := []string{"spec.missing", "spec.nodeName", "spec.replicas", "spec.template", "spec.unschedulable", "status.phase"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected columns %q, got %q", expected, names)
+	}
+	if expected := []interface{}{nil, "node1", "3", nil, "true", "Running"}; !reflect.DeepEqual(expected, table.Rows[0].Cells[2:]) {
+		t.Errorf("expected cells %#v, got %#v", expected, table.Rows[0].Cells[2:])
+	}
+
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithFieldSelectorColumns(fields.Everything()))
+	if table, err = c.ConvertToTable(context.TODO(), obj, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 2 {
+		t.Errorf("expected no column for a selector without requirements, got %#v", table.ColumnDefinitions)
+	}
+
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithFieldSelectorColumns(fields.OneTermEqualSelector("spec[0]", "x")))
+	if _, err := c.ConvertToTable(context.TODO(), obj, nil); err == nil {
+		t.Errorf("expected a field that is not a path to fail the conversion")
+	}
+}
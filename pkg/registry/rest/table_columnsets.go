@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The column sets below follow the fields of the built-in resources they are named after, so that
+// resources with similar shapes render like them. They are meant for NewJSONPathTableConvertor and
+// ColumnBuilder.AddPrinterColumns; every call returns a new slice that callers may extend.
+
+// nameColumn and createdColumn are shared by the column sets. Like DefaultColumns, the creation
+// timestamp is rendered in a "Created At" column; clients render it as an age.
+var (
+	nameColumn    = PrinterColumn{Name: "Name", Type: "string", Format: "name", Description: "Name of the object.", JSONPath: ".metadata.name"}
+	createdColumn = PrinterColumn{Name: "Created At", Type: "date", Description: "Creation timestamp of the object.", JSONPath: ".metadata.creationTimestamp"}
+)
+
+// WorkloadColumns returns the columns of workload resources with replicas, like deployments:
+// the name, the ready replicas out of the desired ones as in "2/3", the up-to-date and available
+// replicas, and the creation timestamp.
+func WorkloadColumns() []PrinterColumn {
+	return []PrinterColumn{
+		nameColumn,
+		{Name: "Ready", Type: "string", Description: "Number of ready replicas out of the desired replicas.", JSONPath: ".status.readyReplicas", cell: func(ctx context.Context, obj runtime.Object) interface{} {
+			return readyReplicas(ctx, obj)
+		}},
+		{Name: "Up-to-date", Type: "integer", Description: "Number of replicas running the latest template.", JSONPath: ".status.updatedReplicas"},
+		{Name: "Available", Type: "integer", Description: "Number of available replicas.", JSONPath: ".status.availableReplicas"},
+		createdColumn,
+	}
+}
+
+// ServiceColumns returns the columns of network resources shaped like services: the name, the
+// type, the cluster IP, the external IPs and the ports as arrays, and the creation timestamp.
+func ServiceColumns() []PrinterColumn {
+	return []PrinterColumn{
+		nameColumn,
+		{Name: "Type", Type: "string", Description: "Type of the service.", JSONPath: ".spec.type"},
+		{Name: "Cluster-IP", Type: "string", Description: "Cluster IP of the service.", JSONPath: ".spec.clusterIP"},
+		{Name: "External-IP", Type: "array", Priority: 1, Description: "External IPs of the service.", JSONPath: ".spec.externalIPs"},
+		{Name: "Ports", Type: "array", Description: "Ports exposed by the service.", JSONPath: ".spec.ports[*].port"},
+		createdColumn,
+	}
+}
+
+// StorageColumns returns the columns of storage resources shaped like persistent volume claims:
+// the name, the phase, the bound volume, the capacity, the access modes as an array, the storage
+// class and the creation timestamp.
+func StorageColumns() []PrinterColumn {
+	return []PrinterColumn{
+		nameColumn,
+		{Name: "Status", Type: "string", Description: "Phase of the claim.", JSONPath: ".status.phase"},
+		{Name: "Volume", Type: "string", Description: "Name of the bound volume.", JSONPath: ".spec.volumeName"},
+		{Name: "Capacity", Type: "string", Format: "quantity", Description: "Storage capacity of the bound volume.", JSONPath: ".status.capacity.storage"},
+		{Name: "Access Modes", Type: "array", Description: "Access modes of the bound volume.", JSONPath: ".status.accessModes"},
+		{Name: "StorageClass", Type: "string", Priority: 1, Description: "Storage class of the claim.", JSONPath: ".spec.storageClassName"},
+		createdColumn,
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func convertWithColumnSet(t *testing.T, columns []PrinterColumn, obj *unstructured.Unstructured) []interface{} {
+	t.Helper()
+	obj.Object["metadata"].(map[string]interface{})["creationTimestamp"] = "2021-06-01T12:00:00Z"
+	c, err := NewJSONPathTableConvertor(columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := c.ConvertToTable(context.TODO(), obj, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateTable(table); err != nil {
+		t.Fatal(err)
+	}
+	for i, def := range table.ColumnDefinitions {
+		if _, ok := table.Rows[0].Cells[i].([]interface{}); table.Rows[0].Cells[i] != nil && ok != (def.Type == "array") {
+			t.Errorf("column %q of type %q has cell %#v", def.Name, def.Type, table.Rows[0].Cells[i])
+		}
+	}
+	return table.Rows[0].Cells
+}
+
+func TestWorkloadColumns(t *testing.T) {
+	deployment := newUnstructured("web", map[string]interface{}{
+		"spec":   map[string]interface{}{"replicas": int64(3)},
+		"status": map[string]interface{}{"readyReplicas": int64(2), "updatedReplicas": int64(3), "availableReplicas": int64(2)},
+	})
+	expected := []interface{}{"web", "2/3", int64(3), int64(2), "2021-06-01T12:00:00Z"}
+	if cells := convertWithColumnSet(t, WorkloadColumns(), deployment); !reflect.DeepEqual(expected, cells) {
+		t.Errorf("expected %#v, got %#v", expected, cells)
+	}
+
+	scaledDown := newUnstructured("idle", map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(0)}})
+	expected = []interface{}{"idle", "0/0", nil, nil, "2021-06-01T12:00:00Z"}
+	if cells := convertWithColumnSet(t, WorkloadColumns(), scaledDown); !reflect.DeepEqual(expected, cells) {
+		t.Errorf("expected %#v, got %#v", expected, cells)
+	}
+}
+
+func TestServiceColumns(t *testing.T) {
+	service := newUnstructured("api", map[string]interface{}{
+		"spec": map[string]interface{}{
+			"type":      "ClusterIP",
+			"clusterIP": "10.0.0.1",
+			"ports":     []interface{}{map[string]interface{}{"port": int64(80)}, map[string]interface{}{"port": int64(443)}},
+		},
+	})
+	expected := []interface{}{"api", "ClusterIP", "10.0.0.1", nil, []interface{}{int64(80), int64(443)}, "2021-06-01T12:00:00Z"}
+	if cells := convertWithColumnSet(t, ServiceColumns(), service); !reflect.DeepEqual(expected, cells) {
+		t.Errorf("expected %#v, got %#v", expected, cells)
+	}
+}
+
+func TestStorageColumns(t *testing.T) {
+	claim := newUnstructured("data", map[string]interface{}{
+		"spec": map[string]interface{}{"volumeName": "pv-1", "storageClassName": "fast"},
+		"status": map[string]interface{}{
+			"phase":       "Bound",
+			"capacity":    map[string]interface{}{"storage": "10240Mi"},
+			"accessModes": []interface{}{"ReadWriteOnce"},
+		},
+	})
+	expected := []interface{}{"data", "Bound", "pv-1", "10Gi", []interface{}{"ReadWriteOnce"}, "fast", "2021-06-01T12:00:00Z"}
+	if cells := convertWithColumnSet(t, StorageColumns(), claim); !reflect.DeepEqual(expected, cells) {
+		t.Errorf("expected %#v, got %#v", expected, cells)
+	}
+}
+
+func TestColumnBuilderAddPrinterColumns(t *testing.T) {
+	b := NewColumnBuilder(schema.GroupResource{Resource: "deployments"}).AddPrinterColumns(WorkloadColumns()...)
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+	table, err := b.AddColumn(metav1.TableColumnDefinition{Name: "Extra", Type: "string"}, func(context.Context, runtime.Object) (interface{}, error) { return "x", nil }).Build().ConvertToTable(context.TODO(), newUnstructured("web", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != len(WorkloadColumns())+1 || table.Rows[0].Cells[0] != "web" || table.Rows[0].Cells[len(WorkloadColumns())] != "x" {
+		t.Errorf("unexpected table: %#v", table)
+	}
+
+	b = NewColumnBuilder(schema.GroupResource{Resource: "deployments"}).
+		AddPrinterColumns(PrinterColumn{Name: "Bad", Type: "string", JSONPath: ".spec[*"}).
+		AddColumn(metav1.TableColumnDefinition{Name: "Extra", Type: "string"}, func(context.Context, runtime.Object) (interface{}, error) { return "x", nil })
+	if err := b.Err(); err == nil {
+		t.Errorf("expected an error for a malformed JSONPath")
+	}
+	if _, err := b.Build().ConvertToTable(context.TODO(), newUnstructured("web", nil), nil); err == nil {
+		t.Errorf("expected the conversions of the built convertor to fail")
+	}
+}
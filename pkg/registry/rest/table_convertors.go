@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TableConvertorFunc is an adapter to use a function as a TableConvertor, like http.HandlerFunc.
+type TableConvertorFunc func(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error)
+
+// ConvertToTable calls f(ctx, object, tableOptions).
+func (f TableConvertorFunc) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	return f(ctx, object, tableOptions)
+}
+
+type chainTableConvertor struct {
+	convertors []TableConvertor
+}
+
+// NewChainTableConvertor returns a convertor that tries each of convertors in order and returns the
+// result of the first one that does not decline the object with a NotAcceptable error. Any other
+// error stops the chain and is returned. If every convertor declines, the error of the last one is
+// returned unchanged.
+func NewChainTableConvertor(convertors ...TableConvertor) TableConvertor {
+	return chainTableConvertor{convertors: convertors}
+}
+
+func (c chainTableConvertor) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	err := newNotAcceptableError(ctx, schema.GroupResource{})
+	for _, convertor := range c.convertors {
+		var table *metav1.Table
+		table, err = convertor.ConvertToTable(ctx, object, tableOptions)
+		if err == nil || !apierrors.IsNotAcceptable(err) {
+			return table, err
+		}
+	}
+	return nil, err
+}
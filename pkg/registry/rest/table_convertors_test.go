@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
+)
+
+type fakeTableConvertor struct {
+	table *metav1.Table
+	err   error
+}
+
+func (f fakeTableConvertor) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	return f.table, f.err
+}
+
+func TestChainTableConvertor(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	declined := errNotAcceptable{resource: schema.GroupResource{Resource: "first"}}
+	lastDeclined := errNotAcceptable{resource: schema.GroupResource{Resource: "last"}}
+	failure := errors.New("failure")
+	specific := &metav1.Table{ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Specific"}}}
+	unreachable := fakeTableConvertor{err: errors.New("the chain should have stopped")}
+
+	tests := []struct {
+		name       string
+		convertors []TableConvertor
+		wantTable  *metav1.Table
+		wantErr    error
+	}{
+		{
+			name:       "first convertor succeeds",
+			convertors: []TableConvertor{fakeTableConvertor{table: specific}, unreachable},
+			wantTable:  specific,
+		},
+		{
+			name:       "fall back after a decline",
+			convertors: []TableConvertor{fakeTableConvertor{err: declined}, fakeTableConvertor{table: specific}, unreachable},
+			wantTable:  specific,
+		},
+		{
+			name:       "other errors short-circuit",
+			convertors: []TableConvertor{fakeTableConvertor{err: failure}, unreachable},
+			wantErr:    failure,
+		},
+		{
+			name:       "all convertors decline",
+			convertors: []TableConvertor{fakeTableConvertor{err: declined}, fakeTableConvertor{err: lastDeclined}},
+			wantErr:    lastDeclined,
+		},
+		{
+			name:    "no convertors",
+			wantErr: errNotAcceptable{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := NewChainTableConvertor(tt.convertors...).ConvertToTable(context.TODO(), pod, nil)
+			if err != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if table != tt.wantTable {
+				t.Errorf("expected table %#v, got %#v", tt.wantTable, table)
+			}
+		})
+	}
+
+	table, err := NewChainTableConvertor(fakeTableConvertor{err: declined}, NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})).ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows[0].Cells[0] != "foo" {
+		t.Errorf("expected the default convertor to render the object, got %#v", table.Rows)
+	}
+}
+
+func TestTableConvertorFunc(t *testing.T) {
+	var c TableConvertor = TableConvertorFunc(func(_ context.Context, object runtime.Object, _ runtime.Object) (*metav1.Table, error) {
+		pod, ok := object.(*example.Pod)
+		if !ok {
+			return nil, errors.New("not a pod")
+		}
+		return &metav1.Table{
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Node", Type: "string"}},
+			Rows:              []metav1.TableRow{{Cells: []interface{}{pod.Spec.NodeName}}},
+		}, nil
+	})
+	table, err := c.ConvertToTable(context.TODO(), &example.Pod{Spec: example.PodSpec{NodeName: "node1"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 1 || len(table.Rows) != 1 || table.Rows[0].Cells[0] != "node1" {
+		t.Errorf("unexpected table: %#v", table)
+	}
+	if _, err := c.ConvertToTable(context.TODO(), &metav1.Status{}, nil); err == nil || err.Error() != "not a pod" {
+		t.Errorf("expected the error of the function, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WithDropEmptyColumns removes from the tables returned by ConvertToTable the columns whose cells
+// are empty, nil or "", in every row, along with their cells, to reduce the noise of optional
+// columns in sparse lists. Columns with the "name" format are always kept, as are all the columns
+// of tables without rows or without column definitions, since the dropped columns could not be
+// told apart. Chunks of ConvertToTableChunk and rows emitted by ConvertToTableStream keep all the
+// columns, since their later rows are not known when the columns are sent.
+func WithDropEmptyColumns() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.dropEmptyColumns = true
+	}
+}
+
+// dropEmptyColumns removes the columns of table whose cells are empty in every row.
+func dropEmptyColumns(table *metav1.Table) {
+	if len(table.Rows) == 0 || len(table.ColumnDefinitions) == 0 {
+		return
+	}
+	keep := make([]bool, len(table.ColumnDefinitions))
+	dropped := false
+	for i, def := range table.ColumnDefinitions {
+		keep[i] = def.Format == "name"
+		for _, row := range table.Rows {
+			if keep[i] {
+				break
+			}
+			keep[i] = !isEmptyCell(cellAt(row, i))
+		}
+		dropped = dropped || !keep[i]
+	}
+	if !dropped {
+		return
+	}
+	// the rows of tables passed through may share their slices with the original table
+	definitions := make([]metav1.TableColumnDefinition, 0, len(table.ColumnDefinitions))
+	for i, def := range table.ColumnDefinitions {
+		if keep[i] {
+			definitions = append(definitions, def)
+		}
+	}
+	table.ColumnDefinitions = definitions
+	for r := range table.Rows {
+		cells := make([]interface{}, 0, len(definitions))
+		for i, cell := range table.Rows[r].Cells {
+			if i >= len(keep) || keep[i] {
+				cells = append(cells, cell)
+			}
+		}
+		table.Rows[r].Cells = cells
+	}
+}
+
+// isEmptyCell reports whether cell renders as nothing.
+func isEmptyCell(cell interface{}) bool {
+	return cell == nil || cell == ""
+}
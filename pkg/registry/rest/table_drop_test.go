@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWithDropEmptyColumns(t *testing.T) {
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("foo", map[string]interface{}{"status": map[string]interface{}{"phase": "Running"}})},
+		{Object: newUnstructured("bar", map[string]interface{}{"status": map[string]interface{}{"phase": "Pending", "reason": "Unschedulable"}})},
+	}}
+	opts := []TableConvertorOption{WithPhaseColumn(), WithStatusMessageColumn(0), WithDropEmptyColumns()}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, opts...)
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, def := range table.ColumnDefinitions {
+		names = append(names, def.Name)
+	}
+	// objects that were never persisted have no creation timestamp, and none has a message
+	if expected := []string{"Name", "Phase", "Reason"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected columns %q, got %q", expected, names)
+	}
+	expected := [][]interface{}{{"foo", "Running", ""}, {"bar", "Pending", "Unschedulable"}}
+	for i, row := range table.Rows {
+		if !reflect.DeepEqual(expected[i], row.Cells) {
+			t.Errorf("row %d: expected cells %#v, got %#v", i, expected[i], row.Cells)
+		}
+	}
+
+	cached := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name", Type: "string", Format: "name"}, {Name: "Node", Type: "string"}},
+		Rows:              []metav1.TableRow{{Cells: []interface{}{"foo", nil}}},
+	}
+	if table, err = c.ConvertToTable(context.TODO(), cached, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 1 || len(table.Rows[0].Cells) != 1 {
+		t.Errorf("expected the empty column of the table to be dropped, got %#v", table)
+	}
+	if len(cached.ColumnDefinitions) != 2 || len(cached.Rows[0].Cells) != 2 || cached.Rows[0].Cells[1] != nil {
+		t.Errorf("expected the converted table to be left unchanged, got %#v", cached)
+	}
+
+	if table, err = c.ConvertToTable(context.TODO(), &metav1.List{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 5 {
+		t.Errorf("expected tables without rows to keep their columns, got %#v", table.ColumnDefinitions)
+	}
+	if table, err = ConvertToTableChunk(context.TODO(), c, list, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 5 {
+		t.Errorf("expected chunks to keep their columns, got %#v", table.ColumnDefinitions)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type tableContextKey int
+
+const (
+	// embeddedEncoderKey is the context key for the encoder of the objects embedded in table rows.
+	embeddedEncoderKey tableContextKey = iota
+	// tableResourceKey is the context key for the resource of table conversions.
+	tableResourceKey
+	// tableVersionKey is the context key for the group version of the tables of conversions.
+	tableVersionKey
+)
+
+// WithEmbeddedObjectEncoder returns a copy of parent in which table convertors encode the objects
+// embedded in table rows with encoder, instead of storing the Go objects. The encoder should
+// produce the media type negotiated for the enclosing Table, since serializers copy
+// RawExtension.Raw verbatim: the protobuf serializer, for instance, drops embedded objects that
+// were not encoded beforehand.
+func WithEmbeddedObjectEncoder(parent context.Context, encoder runtime.Encoder) context.Context {
+	return context.WithValue(parent, embeddedEncoderKey, encoder)
+}
+
+// embeddedObjectEncoderFrom returns the encoder of embedded objects set in ctx, if any.
+func embeddedObjectEncoderFrom(ctx context.Context) (runtime.Encoder, bool) {
+	encoder, ok := ctx.Value(embeddedEncoderKey).(runtime.Encoder)
+	return encoder, ok && encoder != nil
+}
+
+// encodeEmbeddedObject replaces the object of ext with its encoding by encoder. Objects that are
+// already encoded, like *runtime.Unknown, are not encoded again.
+func encodeEmbeddedObject(ext runtime.RawExtension, encoder runtime.Encoder) (runtime.RawExtension, error) {
+	switch obj := ext.Object.(type) {
+	case nil:
+		return ext, nil
+	case *runtime.Unknown:
+		return runtime.RawExtension{Raw: obj.Raw}, nil
+	}
+	raw, err := runtime.Encode(encoder, ext.Object)
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
+
+// WithMaxEmbeddedObjectBytes embeds only the metadata of objects whose serialization exceeds n
+// bytes when the Object includeObject policy is requested, which caps the size of tables of
+// resources with very large objects. The size is that of the encoding set with
+// WithEmbeddedObjectEncoder, or of the JSON encoding of the object otherwise. The cells of the
+// rows are not affected.
+func WithMaxEmbeddedObjectBytes(n int) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.maxEmbeddedObjectBytes = n
+	}
+}
+
+// limitEmbeddedObject returns ext, the embedded object with metadata m, or its metadata at the
+// group version gv if ext is larger than the maximum size of embedded objects. ext is encoded if
+// encode is true.
+func (c *defaultTableConvertor) limitEmbeddedObject(ext runtime.RawExtension, m metav1.Object, gv schema.GroupVersion, encoder runtime.Encoder, encode bool) (runtime.RawExtension, error) {
+	size := len(ext.Raw)
+	if ext.Object != nil {
+		data, err := json.Marshal(ext.Object)
+		if err != nil {
+			return runtime.RawExtension{}, err
+		}
+		size = len(data)
+	}
+	if size <= c.maxEmbeddedObjectBytes {
+		return ext, nil
+	}
+	partial, err := embeddedObject(nil, m, metav1.IncludeMetadata, partialObjectMetadataType(gv))
+	if err != nil || !encode {
+		return partial, err
+	}
+	return encodeEmbeddedObject(partial, encoder)
+}
+
+// WithCanonicalEmbeddedObjects rewrites the JSON encoding of the objects embedded in table rows by
+// the encoder set with WithEmbeddedObjectEncoder in a canonical form, with the keys of every
+// object sorted and no insignificant whitespace, so that equal objects are always embedded as
+// equal bytes, for response caches and golden test fixtures. Embedded objects that are not encoded
+// as JSON, for instance protobuf, are left unchanged, as are objects embedded without an encoder,
+// which are serialized with the enclosing Table. Canonicalizing decodes and encodes every embedded
+// object once more, which costs some CPU per row.
+func WithCanonicalEmbeddedObjects() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.canonicalEmbeddedObjects = true
+	}
+}
+
+// canonicalJSON returns the canonical form of raw if it is a JSON document, or raw otherwise.
+func canonicalJSON(raw []byte) ([]byte, error) {
+	if !json.Valid(raw) {
+		return raw, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	// numbers are kept as they are encoded instead of being rounded to float64
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apiserver/pkg/apis/example"
+)
+
+func TestDefaultTableConvertorEmbeddedObjectEncoder(t *testing.T) {
+	encoder := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{})
+	ctx := WithEmbeddedObjectEncoder(context.TODO(), encoder)
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	table, err := c.ConvertToTable(ctx, pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := table.Rows[0]
+	if row.Object.Object != nil {
+		t.Errorf("expected the embedded object to be encoded, got %#v", row.Object.Object)
+	}
+	if expected := `{"kind":"PartialObjectMetadata","apiVersion":"meta.k8s.io/v1","metadata":{"name":"foo","creationTimestamp":null}}` + "\n"; string(row.Object.Raw) != expected {
+		t.Errorf("unexpected encoding:\n%s\nexpected:\n%s", row.Object.Raw, expected)
+	}
+
+	encoded, err := encodeEmbeddedObject(runtime.RawExtension{Object: &runtime.Unknown{Raw: []byte("raw")}}, encoder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded.Raw) != "raw" || encoded.Object != nil {
+		t.Errorf("expected already encoded objects to be passed through, got %#v", encoded)
+	}
+
+	table, err = c.ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows[0].Object.Raw != nil || table.Rows[0].Object.Object == nil {
+		t.Errorf("expected objects not to be encoded without an encoder, got %#v", table.Rows[0].Object)
+	}
+}
+
+func TestWithMaxEmbeddedObjectBytes(t *testing.T) {
+	encoder := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{})
+	list := &example.PodList{Items: []example.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "small"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "large"}, Spec: example.PodSpec{NodeSelector: map[string]string{"data": strings.Repeat("x", 1024)}}},
+	}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithMaxEmbeddedObjectBytes(512))
+	options := &metav1.TableOptions{IncludeObject: metav1.IncludeObject}
+
+	table, err := c.ConvertToTable(context.TODO(), list, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows[0].Object.Object != &list.Items[0] {
+		t.Errorf("expected the small object to be embedded, got %#v", table.Rows[0].Object.Object)
+	}
+	partial, ok := table.Rows[1].Object.Object.(*metav1.PartialObjectMetadata)
+	if !ok || partial.Name != "large" {
+		t.Errorf("expected the metadata of the large object to be embedded, got %#v", table.Rows[1].Object.Object)
+	}
+	if table.Rows[1].Cells[0] != "large" {
+		t.Errorf("expected the cells to be intact, got %v", table.Rows[1].Cells)
+	}
+
+	table, err = c.ConvertToTable(WithEmbeddedObjectEncoder(context.TODO(), encoder), list, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw := string(table.Rows[0].Object.Raw); !strings.Contains(raw, `"name":"small"`) || strings.Contains(raw, "PartialObjectMetadata") {
+		t.Errorf("expected the small object to be encoded, got %s", raw)
+	}
+	if raw := string(table.Rows[1].Object.Raw); !strings.Contains(raw, `"kind":"PartialObjectMetadata"`) || len(raw) > 512 {
+		t.Errorf("expected the metadata of the large object to be encoded, got %s", raw)
+	}
+
+	table, err = c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := table.Rows[1].Object.Object.(*metav1.PartialObjectMetadata); !ok {
+		t.Errorf("expected the Metadata policy to be unaffected, got %#v", table.Rows[1].Object.Object)
+	}
+}
+
+// fixedEncoder encodes every object as the same bytes.
+type fixedEncoder []byte
+
+func (e fixedEncoder) Encode(_ runtime.Object, w io.Writer) error {
+	_, err := w.Write(e)
+	return err
+}
+
+func (e fixedEncoder) Identifier() runtime.Identifier { return "fixed" }
+
+func TestWithCanonicalEmbeddedObjects(t *testing.T) {
+	unordered := fixedEncoder(`{"metadata": {"name": "foo", "labels": {"b": "2", "a": "1"}}, "kind": "PartialObjectMetadata", "size": 12345678901234567890}` + "\n")
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	testCases := []struct {
+		name     string
+		encoder  runtime.Encoder
+		options  []TableConvertorOption
+		expected string
+	}{
+		{name: "disabled", encoder: unordered, expected: string(unordered)},
+		{name: "json", encoder: unordered, options: []TableConvertorOption{WithCanonicalEmbeddedObjects()}, expected: `{"kind":"PartialObjectMetadata","metadata":{"labels":{"a":"1","b":"2"},"name":"foo"},"size":12345678901234567890}`},
+		{name: "not json", encoder: fixedEncoder("\x0a\x03foo"), options: []TableConvertorOption{WithCanonicalEmbeddedObjects()}, expected: "\x0a\x03foo"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, tc.options...)
+			table, err := c.ConvertToTable(WithEmbeddedObjectEncoder(context.TODO(), tc.encoder), pod, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if raw := string(table.Rows[0].Object.Raw); raw != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, raw)
+			}
+		})
+	}
+
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithCanonicalEmbeddedObjects()).ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows[0].Object.Raw != nil || table.Rows[0].Object.Object == nil {
+		t.Errorf("expected objects not to be encoded without an encoder, got %#v", table.Rows[0].Object)
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FormatCell normalizes value for a cell of a column of the given OpenAPI type and format, so that
+// clients render cells consistently:
+//
+//   - "date" cells holding a time.Time or metav1.Time become RFC3339 strings in UTC
+//   - "integer" cells holding any Go integer, or a float without fractional part, become int64
+//   - "number" cells holding any Go integer or float become float64
+//   - "boolean" cells holding a *bool become a bool, so that JSON tables keep the raw boolean
+//   - "string" cells holding a bool or a *bool become "True" or "False", like the status of
+//     conditions shown by kubectl
+//   - "string" cells of the "quantity" format holding a resource.Quantity, an integer number of
+//     bytes or a quantity string become canonical quantity strings, e.g. "1Gi"; strings that are
+//     not valid quantities are returned unchanged
+//
+// Values of other types, and cells of other column types, are returned unchanged. Numbers of
+// "string" cells can be rendered with a unit or thousands separators with NumberFormat.
+func FormatCell(colType, format string, value interface{}) interface{} {
+	switch colType {
+	case "date":
+		switch t := value.(type) {
+		case time.Time:
+			return t.UTC().Format(time.RFC3339)
+		case metav1.Time:
+			return t.UTC().Format(time.RFC3339)
+		case *metav1.Time:
+			if t == nil {
+				return nil
+			}
+			return t.UTC().Format(time.RFC3339)
+		}
+	case "integer":
+		if i, ok := toInt64(value); ok {
+			return i
+		}
+		if f, ok := toFloat64(value); ok && f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+			return int64(f)
+		}
+	case "number":
+		if f, ok := toFloat64(value); ok {
+			return f
+		}
+		if i, ok := toInt64(value); ok {
+			return float64(i)
+		}
+	case "boolean":
+		if b, ok := value.(*bool); ok {
+			if b == nil {
+				return nil
+			}
+			return *b
+		}
+	case "string":
+		switch b := value.(type) {
+		case bool:
+			return formatBool(b)
+		case *bool:
+			if b == nil {
+				return nil
+			}
+			return formatBool(*b)
+		}
+		if format == "quantity" {
+			return formatQuantity(value)
+		}
+	}
+	return value
+}
+
+// NumberFormat renders the integers and floats of "string" cells as text, for numbers that read
+// better with a unit or with thousands separators. Cells of "integer" and "number" columns are
+// left to FormatCell, so that JSON tables keep their numbers raw.
+type NumberFormat struct {
+	// Unit is appended as is to the numbers, e.g. "Mi" to render 512 as "512Mi".
+	Unit string
+	// Thousands separates the thousands of the integer part of the numbers with commas, e.g.
+	// "1,024".
+	Thousands bool
+}
+
+// FormatCell returns the number held by value in the format of f if colType is "string" and the
+// format is not "quantity", and the cell returned by FormatCell otherwise.
+func (f NumberFormat) FormatCell(colType, format string, value interface{}) interface{} {
+	if colType != "string" || format == "quantity" || f == (NumberFormat{}) {
+		return FormatCell(colType, format, value)
+	}
+	var number string
+	if i, ok := toInt64(value); ok {
+		number = strconv.FormatInt(i, 10)
+	} else if x, ok := toFloat64(value); ok && !math.IsInf(x, 0) && !math.IsNaN(x) {
+		number = strconv.FormatFloat(x, 'f', -1, 64)
+	} else {
+		return FormatCell(colType, format, value)
+	}
+	if f.Thousands {
+		number = separateThousands(number)
+	}
+	return number + f.Unit
+}
+
+// separateThousands inserts commas between the thousands of the integer part of number, a decimal
+// number with an optional sign and fractional part.
+func separateThousands(number string) string {
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+	integer, fraction := number, ""
+	if i := strings.IndexByte(number, '.'); i >= 0 {
+		integer, fraction = number[:i], number[i:]
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	b.WriteString(fraction)
+	return b.String()
+}
+
+// FormatMapCell renders m, for instance labels or annotations, as comma separated key=value pairs
+// sorted by key, e.g. "app=web,tier=frontend", so that cells do not depend on the iteration order
+// of the map. An empty or nil map renders as "".
+func FormatMapCell(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(m[k])
+	}
+	return b.String()
+}
+
+// FormatArrayCell renders values, for instance the external IPs of a service, as text: the values
+// separated by commas in order, e.g. "10.0.0.1,10.0.0.2". An empty or nil slice renders as "".
+func FormatArrayCell(values []string) string {
+	return strings.Join(values, ",")
+}
+
+// formatBool returns the Kubernetes spelling of b, "True" or "False".
+func formatBool(b bool) string {
+	if b {
+		return string(metav1.ConditionTrue)
+	}
+	return string(metav1.ConditionFalse)
+}
+
+// formatQuantity returns the canonical quantity string of value, or value if it is not a quantity.
+func formatQuantity(value interface{}) interface{} {
+	switch q := value.(type) {
+	case resource.Quantity:
+		return q.String()
+	case *resource.Quantity:
+		if q == nil {
+			return nil
+		}
+		return q.String()
+	case string:
+		if parsed, err := resource.ParseQuantity(q); err == nil {
+			return parsed.String()
+		}
+		return value
+	}
+	if i, ok := toInt64(value); ok {
+		return resource.NewQuantity(i, resource.BinarySI).String()
+	}
+	if f, ok := toFloat64(value); ok && f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+		return resource.NewQuantity(int64(f), resource.BinarySI).String()
+	}
+	return value
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch i := value.(type) {
+	case int:
+		return int64(i), true
+	case int8:
+		return int64(i), true
+	case int16:
+		return int64(i), true
+	case int32:
+		return int64(i), true
+	case int64:
+		return i, true
+	case uint:
+		return int64(i), uint64(i) <= math.MaxInt64
+	case uint8:
+		return int64(i), true
+	case uint16:
+		return int64(i), true
+	case uint32:
+		return int64(i), true
+	case uint64:
+		return int64(i), i <= math.MaxInt64
+	}
+	return 0, false
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch f := value.(type) {
+	case float32:
+		return float64(f), true
+	case float64:
+		return f, true
+	}
+	return 0, false
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFormatCell(t *testing.T) {
+	local := time.Date(2021, time.June, 1, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	metaTime := metav1.NewTime(local)
+	yes := true
+	tests := []struct {
+		name    string
+		colType string
+		format  string
+		value   interface{}
+		want    interface{}
+	}{
+		{name: "time", colType: "date", value: local, want: "2021-06-01T12:00:00Z"},
+		{name: "meta time", colType: "date", value: metaTime, want: "2021-06-01T12:00:00Z"},
+		{name: "meta time pointer", colType: "date", value: &metaTime, want: "2021-06-01T12:00:00Z"},
+		{name: "nil meta time pointer", colType: "date", value: (*metav1.Time)(nil), want: nil},
+		{name: "date string", colType: "date", value: "2021-06-01T12:00:00Z", want: "2021-06-01T12:00:00Z"},
+		{name: "int32", colType: "integer", value: int32(3), want: int64(3)},
+		{name: "uint", colType: "integer", value: uint(3), want: int64(3)},
+		{name: "integral float", colType: "integer", value: float64(3), want: int64(3)},
+		{name: "fractional float", colType: "integer", value: 3.5, want: 3.5},
+		{name: "integer string", colType: "integer", value: "3", want: "3"},
+		{name: "out of range float", colType: "integer", value: 1e19, want: 1e19},
+		{name: "infinite float", colType: "integer", value: math.Inf(1), want: math.Inf(1)},
+		{name: "max int64 uint64", colType: "integer", value: uint64(math.MaxInt64), want: int64(math.MaxInt64)},
+		{name: "out of range uint64", colType: "integer", value: uint64(math.MaxUint64), want: uint64(math.MaxUint64)},
+		{name: "out of range uint64 number", colType: "number", value: uint64(math.MaxUint64), want: uint64(math.MaxUint64)},
+		{name: "float32", colType: "number", value: float32(1.5), want: float64(1.5)},
+		{name: "int number", colType: "number", value: 2, want: float64(2)},
+		{name: "string", colType: "string", value: 2, want: 2},
+		{name: "bool string", colType: "string", value: true, want: "True"},
+		{name: "false string", colType: "string", value: false, want: "False"},
+		{name: "bool pointer string", colType: "string", value: &yes, want: "True"},
+		{name: "nil bool pointer string", colType: "string", value: (*bool)(nil), want: nil},
+		{name: "boolean", colType: "boolean", value: false, want: false},
+		{name: "bool pointer boolean", colType: "boolean", value: &yes, want: true},
+		{name: "nil bool pointer boolean", colType: "boolean", value: (*bool)(nil), want: nil},
+		{name: "string boolean", colType: "boolean", value: "True", want: "True"},
+		{name: "unknown type", colType: "unknown", value: local, want: local},
+		{name: "quantity", colType: "string", format: "quantity", value: resource.MustParse("1024Mi"), want: "1Gi"},
+		{name: "quantity pointer", colType: "string", format: "quantity", value: resource.NewMilliQuantity(1500, resource.DecimalSI), want: "1500m"},
+		{name: "nil quantity pointer", colType: "string", format: "quantity", value: (*resource.Quantity)(nil), want: nil},
+		{name: "byte count", colType: "string", format: "quantity", value: int64(2 * 1024 * 1024 * 1024), want: "2Gi"},
+		{name: "float byte count", colType: "string", format: "quantity", value: float64(1024), want: "1Ki"},
+		{name: "quantity string", colType: "string", format: "quantity", value: "2048Ki", want: "2Mi"},
+		{name: "malformed quantity", colType: "string", format: "quantity", value: "lots", want: "lots"},
+		{name: "fractional byte count", colType: "string", format: "quantity", value: 1.5, want: 1.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatCell(tt.colType, tt.format, tt.value); !reflect.DeepEqual(tt.want, got) {
+				t.Errorf("expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNumberFormatFormatCell(t *testing.T) {
+	tests := []struct {
+		name    string
+		f       NumberFormat
+		colType string
+		format  string
+		value   interface{}
+		want    interface{}
+	}{
+		{name: "thousands", f: NumberFormat{Thousands: true}, colType: "string", value: int64(1024), want: "1,024"},
+		{name: "millions", f: NumberFormat{Thousands: true}, colType: "string", value: int32(-1234567), want: "-1,234,567"},
+		{name: "hundreds", f: NumberFormat{Thousands: true}, colType: "string", value: 999, want: "999"},
+		{name: "float thousands", f: NumberFormat{Thousands: true}, colType: "string", value: 12345.678, want: "12,345.678"},
+		{name: "unit", f: NumberFormat{Unit: "Mi"}, colType: "string", value: int64(512), want: "512Mi"},
+		{name: "float unit", f: NumberFormat{Unit: "ms"}, colType: "string", value: 1.5, want: "1.5ms"},
+		{name: "unit and thousands", f: NumberFormat{Unit: " B", Thousands: true}, colType: "string", value: uint64(4096), want: "4,096 B"},
+		{name: "string value", f: NumberFormat{Unit: "Mi", Thousands: true}, colType: "string", value: "1024", want: "1024"},
+		{name: "bool value", f: NumberFormat{Unit: "Mi"}, colType: "string", value: true, want: "True"},
+		{name: "integer column", f: NumberFormat{Unit: "Mi", Thousands: true}, colType: "integer", value: int32(1024), want: int64(1024)},
+		{name: "number column", f: NumberFormat{Unit: "Mi", Thousands: true}, colType: "number", value: 1024, want: float64(1024)},
+		{name: "quantity format", f: NumberFormat{Unit: "Mi", Thousands: true}, colType: "string", format: "quantity", value: int64(1024), want: "1Ki"},
+		{name: "no format", colType: "string", value: 1024, want: 1024},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.f.FormatCell(tc.colType, tc.format, tc.value); !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("expected %#v, got %#v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatMapCell(t *testing.T) {
+	tests := []struct {
+		m    map[string]string
+		want string
+	}{
+		{m: nil, want: ""},
+		{m: map[string]string{}, want: ""},
+		{m: map[string]string{"app": "web"}, want: "app=web"},
+		{m: map[string]string{"tier": "frontend", "app": "web", "env": ""}, want: "app=web,env=,tier=frontend"},
+		{m: map[string]string{"k8s.io/b": "2", "k8s.io/a": "1", "a": "0"}, want: "a=0,k8s.io/a=1,k8s.io/b=2"},
+	}
+	for _, tc := range tests {
+		for i := 0; i < 10; i++ {
+			if got := FormatMapCell(tc.m); got != tc.want {
+				t.Errorf("%v: expected %q, got %q", tc.m, tc.want, got)
+			}
+		}
+	}
+}
+
+func TestFormatArrayCell(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{values: nil, want: ""},
+		{values: []string{}, want: ""},
+		{values: []string{"10.0.0.1"}, want: "10.0.0.1"},
+		{values: []string{"10.0.0.2", "10.0.0.1", ""}, want: "10.0.0.2,10.0.0.1,"},
+	}
+	for _, tc := range tests {
+		if got := FormatArrayCell(tc.values); got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.values, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// validColumnTypes are the OpenAPI types of table columns.
+var validColumnTypes = []string{"array", "boolean", "date", "integer", "number", "string"}
+
+// ValidColumnType reports whether t is a valid type of table column: "string", "integer", "number",
+// "boolean", "date" or "array".
+func ValidColumnType(t string) bool {
+	for _, valid := range validColumnTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTable checks that every row of table has one cell per column definition, when column
+// definitions are present, and that every cell can be serialized to JSON. The returned error
+// aggregates one error per offending row.
+func ValidateTable(table *metav1.Table) error {
+	if table == nil {
+		return fmt.Errorf("table must not be nil")
+	}
+	var errs []error
+	for i, row := range table.Rows {
+		if len(table.ColumnDefinitions) > 0 && len(row.Cells) != len(table.ColumnDefinitions) {
+			errs = append(errs, fmt.Errorf("row %d has %d cells, but the table has %d columns", i, len(row.Cells), len(table.ColumnDefinitions)))
+			continue
+		}
+		for j, cell := range row.Cells {
+			if _, err := json.Marshal(cell); err != nil {
+				errs = append(errs, fmt.Errorf("row %d cell %d of type %T cannot be serialized: %v", i, j, cell, err))
+				break
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// RowErrors returns the errors of the rows of table with a RowConversionFailed condition, in an
+// aggregate with one error per row, or nil if every row was converted successfully.
+func RowErrors(table *metav1.Table) error {
+	if table == nil {
+		return nil
+	}
+	var errs []error
+	for i, row := range table.Rows {
+		for _, condition := range row.Conditions {
+			if condition.Type == RowConversionFailed && condition.Status == metav1.ConditionTrue {
+				errs = append(errs, fmt.Errorf("row %d: %s", i, condition.Message))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateCell checks that cell is a string, a number, a boolean or nil, or a slice or a map with
+// string keys of such values.
+func validateCell(cell interface{}) error {
+	if cell == nil {
+		return nil
+	}
+	value := reflect.ValueOf(cell)
+	switch value.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := validateCell(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported cell type %T: map keys must be strings", cell)
+		}
+		iter := value.MapRange()
+		for iter.Next() {
+			if err := validateCell(iter.Value().Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported cell type %T", cell)
+	}
+}
+
+// FilterColumnsByPriority returns a copy of table without the columns whose priority is greater
+// than maxPriority, along with their cells. Priority 0 columns are always shown by clients, higher
+// priorities are only shown in wide output. Tables without column definitions, such as tables
+// converted without headers and the chunks following the first one, are copied with all their
+// cells, since the priorities of their columns are unknown. The input table is not modified.
+func FilterColumnsByPriority(table *metav1.Table, maxPriority int32) *metav1.Table {
+	if table == nil {
+		return nil
+	}
+	if len(table.ColumnDefinitions) == 0 {
+		filtered := *table
+		filtered.Rows = append([]metav1.TableRow(nil), table.Rows...)
+		return &filtered
+	}
+	keep := make([]bool, len(table.ColumnDefinitions))
+	filtered := *table
+	filtered.ColumnDefinitions = make([]metav1.TableColumnDefinition, 0, len(table.ColumnDefinitions))
+	for i, def := range table.ColumnDefinitions {
+		if def.Priority <= maxPriority {
+			keep[i] = true
+			filtered.ColumnDefinitions = append(filtered.ColumnDefinitions, def)
+		}
+	}
+	filtered.Rows = make([]metav1.TableRow, len(table.Rows))
+	for i, row := range table.Rows {
+		cells := make([]interface{}, 0, len(filtered.ColumnDefinitions))
+		for j, cell := range row.Cells {
+			if j < len(keep) && keep[j] {
+				cells = append(cells, cell)
+			}
+		}
+		row.Cells = cells
+		filtered.Rows[i] = row
+	}
+	return &filtered
+}
+
+// TableToPartialObjectMetadataList returns the metadata of the objects embedded in the rows of
+// table, which were converted with the Metadata or Object includeObject policy, along with the
+// list metadata of the table. Embedded objects may be Go objects or JSON encoded.
+func TableToPartialObjectMetadataList(table *metav1.Table) (*metav1.PartialObjectMetadataList, error) {
+	if table == nil {
+		return nil, fmt.Errorf("table must not be nil")
+	}
+	list := &metav1.PartialObjectMetadataList{ListMeta: table.ListMeta}
+	list.GetObjectKind().SetGroupVersionKind(metav1.SchemeGroupVersion.WithKind("PartialObjectMetadataList"))
+	for i, row := range table.Rows {
+		var partial *metav1.PartialObjectMetadata
+		switch {
+		case row.Object.Object != nil:
+			m, err := meta.Accessor(row.Object.Object)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: embedded object %T has no metadata: %v", i, row.Object.Object, err)
+			}
+			partial = meta.AsPartialObjectMetadata(m)
+		case row.Object.Raw != nil:
+			partial = &metav1.PartialObjectMetadata{}
+			if err := json.Unmarshal(row.Object.Raw, partial); err != nil {
+				return nil, fmt.Errorf("row %d: unable to decode embedded object: %v", i, err)
+			}
+		default:
+			return nil, fmt.Errorf("row %d has no embedded object", i)
+		}
+		partial.GetObjectKind().SetGroupVersionKind(metav1.SchemeGroupVersion.WithKind("PartialObjectMetadata"))
+		list.Items = append(list.Items, *partial)
+	}
+	return list, nil
+}
+
+// ConvertObjectsToTable converts objs with c to a single table with a row per object, in order, as
+// if they were the items of a list. The table has no list metadata: its resource version,
+// continue token and remaining item count are empty. A nil or empty objs converts to a table
+// without rows, with column definitions unless tableOptions disable headers.
+func ConvertObjectsToTable(ctx context.Context, objs []runtime.Object, tableOptions runtime.Object, c TableConvertor) (*metav1.Table, error) {
+	list := &metav1.List{Items: make([]runtime.RawExtension, len(objs))}
+	for i, obj := range objs {
+		list.Items[i].Object = obj
+	}
+	return c.ConvertToTable(ctx, list, tableOptions)
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
+)
+
+func TestFilterColumnsByPriority(t *testing.T) {
+	c := NewColumnBuilder(schema.GroupResource{Resource: "pods"}).
+		AddColumn(metav1.TableColumnDefinition{Name: "Name", Type: "string", Format: "name"}, func(_ context.Context, obj runtime.Object) (interface{}, error) {
+			return obj.(*example.Pod).Name, nil
+		}).
+		AddColumn(metav1.TableColumnDefinition{Name: "Node", Type: "string", Priority: 1}, ContextFreeExtractor(podNodeName)).
+		AddColumn(metav1.TableColumnDefinition{Name: "Hostname", Type: "string", Priority: 2}, func(_ context.Context, obj runtime.Object) (interface{}, error) {
+			return obj.(*example.Pod).Spec.Hostname, nil
+		}).
+		Build()
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Spec: example.PodSpec{NodeName: "node", Hostname: "host"}}
+	table, err := c.ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priorities := []int32{table.ColumnDefinitions[0].Priority, table.ColumnDefinitions[1].Priority, table.ColumnDefinitions[2].Priority}; !reflect.DeepEqual(priorities, []int32{0, 1, 2}) {
+		t.Fatalf("expected registered priorities to be emitted, got %v", priorities)
+	}
+
+	tests := []struct {
+		maxPriority int32
+		columns     []string
+		cells       []interface{}
+	}{
+		{maxPriority: 0, columns: []string{"Name"}, cells: []interface{}{"foo"}},
+		{maxPriority: 1, columns: []string{"Name", "Node"}, cells: []interface{}{"foo", "node"}},
+		{maxPriority: 2, columns: []string{"Name", "Node", "Hostname"}, cells: []interface{}{"foo", "node", "host"}},
+	}
+	for _, tt := range tests {
+		filtered := FilterColumnsByPriority(table, tt.maxPriority)
+		var columns []string
+		for _, def := range filtered.ColumnDefinitions {
+			columns = append(columns, def.Name)
+		}
+		if !reflect.DeepEqual(columns, tt.columns) {
+			t.Errorf("priority %d: expected columns %v, got %v", tt.maxPriority, tt.columns, columns)
+		}
+		if !reflect.DeepEqual(filtered.Rows[0].Cells, tt.cells) {
+			t.Errorf("priority %d: expected cells %v, got %v", tt.maxPriority, tt.cells, filtered.Rows[0].Cells)
+		}
+	}
+	if len(table.ColumnDefinitions) != 3 || len(table.Rows[0].Cells) != 3 {
+		t.Errorf("expected the input table to be left unmodified")
+	}
+
+	headless, err := c.ConvertToTable(context.TODO(), pod, &metav1.TableOptions{NoHeaders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered := FilterColumnsByPriority(headless, 0)
+	if expected := []interface{}{"foo", "node", "host"}; filtered.ColumnDefinitions != nil || !reflect.DeepEqual(expected, filtered.Rows[0].Cells) {
+		t.Errorf("expected the cells of a table without headers to be kept, got %#v", filtered)
+	}
+}
+
+func TestValidateTable(t *testing.T) {
+	columns := []metav1.TableColumnDefinition{{Name: "Name", Type: "string"}, {Name: "Count", Type: "integer"}}
+	tests := []struct {
+		name   string
+		table  *metav1.Table
+		errors []string
+	}{
+		{
+			name:  "valid",
+			table: &metav1.Table{ColumnDefinitions: columns, Rows: []metav1.TableRow{{Cells: []interface{}{"a", 1}}, {Cells: []interface{}{"b", int64(2)}}}},
+		},
+		{
+			name:  "no headers",
+			table: &metav1.Table{Rows: []metav1.TableRow{{Cells: []interface{}{"a"}}, {Cells: []interface{}{"b", 2, true}}}},
+		},
+		{
+			name: "mismatched and unserializable rows",
+			table: &metav1.Table{ColumnDefinitions: columns, Rows: []metav1.TableRow{
+				{Cells: []interface{}{"a", 1}},
+				{Cells: []interface{}{"b"}},
+				{Cells: []interface{}{"c", func() {}}},
+			}},
+			errors: []string{"row 1 has 1 cells, but the table has 2 columns", "row 2 cell 1 of type func()"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTable(tt.table)
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v", tt.errors)
+			}
+			for _, msg := range tt.errors {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("expected error to contain %q, got %v", msg, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultTableConvertorWithTableValidation(t *testing.T) {
+	b := NewColumnBuilder(schema.GroupResource{Resource: "pods"}).
+		AddColumn(metav1.TableColumnDefinition{Name: "Channel", Type: "string"}, func(context.Context, runtime.Object) (interface{}, error) {
+			return make(chan int), nil
+		})
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	if _, err := b.Build().ConvertToTable(context.TODO(), pod, nil); err != nil {
+		t.Fatalf("expected validation to be disabled by default, got %v", err)
+	}
+	if _, err := b.Build(WithTableValidation()).ConvertToTable(context.TODO(), pod, nil); err == nil {
+		t.Fatalf("expected validation to reject the table")
+	}
+}
+
+func TestTableToPartialObjectMetadataList(t *testing.T) {
+	list := &example.PodList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "7", Continue: "next"},
+		Items: []example.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"app": "web"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns"}},
+		},
+	}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+	for _, policy := range []metav1.IncludeObjectPolicy{metav1.IncludeMetadata, metav1.IncludeObject} {
+		table, err := c.ConvertToTable(context.TODO(), list, &metav1.TableOptions{IncludeObject: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		partials, err := TableToPartialObjectMetadataList(table)
+		if err != nil {
+			t.Fatalf("%s: %v", policy, err)
+		}
+		if partials.ResourceVersion != "7" || partials.Continue != "next" || partials.Kind != "PartialObjectMetadataList" {
+			t.Errorf("%s: unexpected list: %#v", policy, partials)
+		}
+		if len(partials.Items) != 2 {
+			t.Fatalf("%s: expected 2 items, got %d", policy, len(partials.Items))
+		}
+		for i, item := range partials.Items {
+			if !reflect.DeepEqual(item.ObjectMeta, list.Items[i].ObjectMeta) || item.Kind != "PartialObjectMetadata" {
+				t.Errorf("%s: unexpected item %d: %#v", policy, i, item)
+			}
+		}
+	}
+
+	encoded := &metav1.Table{Rows: []metav1.TableRow{{Object: runtime.RawExtension{Raw: []byte(`{"kind":"PartialObjectMetadata","apiVersion":"meta.k8s.io/v1","metadata":{"name":"c"}}`)}}}}
+	partials, err := TableToPartialObjectMetadataList(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partials.Items[0].Name != "c" {
+		t.Errorf("unexpected decoded item: %#v", partials.Items[0])
+	}
+
+	table, err := c.ConvertToTable(context.TODO(), list, &metav1.TableOptions{IncludeObject: metav1.IncludeNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TableToPartialObjectMetadataList(table); err == nil {
+		t.Errorf("expected an error for rows without embedded objects")
+	}
+}
+
+func TestConvertObjectsToTable(t *testing.T) {
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+	objs := []runtime.Object{
+		&example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+		newUnstructured("a", nil),
+	}
+	table, err := ConvertObjectsToTable(context.TODO(), objs, nil, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 2 || table.Rows[0].Cells[0] != "b" || table.Rows[1].Cells[0] != "a" {
+		t.Errorf("expected a row per object in order, got %#v", table.Rows)
+	}
+	if !reflect.DeepEqual(table.ListMeta, metav1.ListMeta{}) {
+		t.Errorf("expected empty list metadata, got %#v", table.ListMeta)
+	}
+
+	table, err = ConvertObjectsToTable(context.TODO(), nil, nil, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 0 || len(table.ColumnDefinitions) == 0 {
+		t.Errorf("expected the headers of a table without rows, got %#v", table)
+	}
+
+	if _, err := ConvertObjectsToTable(context.TODO(), []runtime.Object{&metav1.Status{}}, nil, c); err == nil {
+		t.Errorf("expected objects without metadata to fail the conversion")
+	}
+}
+
+func TestValidColumnType(t *testing.T) {
+	for colType, valid := range map[string]bool{
+		"string":  true,
+		"integer": true,
+		"number":  true,
+		"boolean": true,
+		"date":    true,
+		"array":   true,
+		"":        false,
+		"str":     false,
+		"Integer": false,
+		"object":  false,
+	} {
+		if got := ValidColumnType(colType); got != valid {
+			t.Errorf("%q: expected %v, got %v", colType, valid, got)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/utils/clock"
+)
+
+// PrinterColumn describes a column whose cells are extracted from objects with a JSONPath
+// expression, like the additionalPrinterColumns of a CustomResourceDefinition.
+type PrinterColumn struct {
+	// Name is the human readable name of the column.
+	Name string
+	// Type is the OpenAPI type of the column, e.g. "string", "integer" or "date".
+	Type string
+	// Format is an optional OpenAPI format modifier of the column, e.g. "name".
+	Format string
+	// Description is a human readable description of the column.
+	Description string
+	// Priority is 0 for columns always shown, higher for columns only shown in wide output.
+	Priority int32
+	// JSONPath is a simple JSONPath expression evaluated against every object, e.g. ".spec.replicas".
+	JSONPath string
+	// Unit and Thousands format the numbers of "string" columns, see NumberFormat.
+	Unit      string
+	Thousands bool
+
+	// cell, if set, renders the cells of the column in place of JSONPath, which then only documents
+	// the column. It is set by the column sets for cells combining several fields.
+	cell func(ctx context.Context, obj runtime.Object) interface{}
+}
+
+// definition returns the table column definition of col.
+func (col PrinterColumn) definition() metav1.TableColumnDefinition {
+	return metav1.TableColumnDefinition{
+		Name:        col.Name,
+		Type:        col.Type,
+		Format:      col.Format,
+		Description: col.Description,
+		Priority:    col.Priority,
+	}
+}
+
+// numberFormat returns the format of the numbers of col.
+func (col PrinterColumn) numberFormat() NumberFormat {
+	return NumberFormat{Unit: col.Unit, Thousands: col.Thousands}
+}
+
+// NewJSONPathTableConvertor creates a convertor rendering one column per printer column. An error
+// is returned if any of the JSONPath expressions is malformed or any of the types is not valid
+// according to ValidColumnType. Paths that are missing from an object render an empty (nil) cell.
+func NewJSONPathTableConvertor(columns []PrinterColumn) (TableConvertor, error) {
+	c := &defaultTableConvertor{clock: clock.RealClock{}}
+	var tableColumns []column
+	for _, col := range columns {
+		tableColumn, err := printerColumn(col)
+		if err != nil {
+			return nil, err
+		}
+		tableColumns = append(tableColumns, tableColumn)
+	}
+	c.setColumns(tableColumns)
+	return c, nil
+}
+
+// ConvertToTableWithColumns implements TableColumnsConvertor. The columns of c, including the
+// extra columns, are replaced by the requested columns, while the other options of c still apply.
+// A bad request error is returned, before any row is converted, if a JSONPath is malformed or a
+// type is not valid according to ValidColumnType.
+func (c *defaultTableConvertor) ConvertToTableWithColumns(ctx context.Context, object runtime.Object, tableOptions runtime.Object, columns []PrinterColumn) (*metav1.Table, error) {
+	var tableColumns []column
+	for _, col := range columns {
+		tableColumn, err := printerColumn(col)
+		if err != nil {
+			return nil, apierrors.NewBadRequest(err.Error())
+		}
+		tableColumns = append(tableColumns, tableColumn)
+	}
+	requested := c.withColumnsOnly()
+	requested.setColumns(tableColumns)
+	return requested.ConvertToTable(ctx, object, tableOptions)
+}
+
+// printerColumn returns the column of col like jsonPathColumn, or an error if the type of col is
+// not valid according to ValidColumnType.
+func printerColumn(col PrinterColumn) (column, error) {
+	if err := validateColumnType(col.definition()); err != nil {
+		return column{}, err
+	}
+	return jsonPathColumn(col)
+}
+
+// jsonPathColumn returns a column extracting its cells with the JSONPath expression of col.
+func jsonPathColumn(col PrinterColumn) (column, error) {
+	find, err := jsonPathFinder(col.Name, col.JSONPath)
+	if err != nil {
+		return column{}, err
+	}
+	return column{
+		definition: col.definition(),
+		jsonPath:   col.JSONPath,
+		cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+			if col.cell != nil {
+				return col.cell(ctx, obj), nil
+			}
+			content, err := rowContent(ctx, obj)
+			if err != nil {
+				return nil, err
+			}
+			values := find(content)
+			switch {
+			case len(values) == 0:
+				return nil, nil
+			case len(values) == 1:
+				return col.numberFormat().FormatCell(col.Type, col.Format, values[0]), nil
+			case col.Type == "string":
+				// the values of string columns are joined like kubectl and the printer columns of
+				// custom resources do, other columns render them as an array
+				formatted := make([]string, 0, len(values))
+				for _, value := range values {
+					formatted = append(formatted, fmt.Sprint(col.numberFormat().FormatCell(col.Type, col.Format, value)))
+				}
+				return FormatArrayCell(formatted), nil
+			}
+			return values, nil
+		},
+	}, nil
+}
+
+// jsonPathFinder returns a function returning the values found with jsonPath in data, or nil if
+// there is none. An error is returned if jsonPath is malformed.
+func jsonPathFinder(name, jsonPath string) (func(data interface{}) []interface{}, error) {
+	template := fmt.Sprintf("{%s}", jsonPath)
+	if _, err := parseJSONPath(name, template); err != nil {
+		return nil, fmt.Errorf("unrecognized column definition %q: %v", jsonPath, err)
+	}
+	// JSONPath keeps state while finding results, so every conversion takes a parser from the pool
+	parsers := &sync.Pool{
+		New: func() interface{} {
+			parser, _ := parseJSONPath(name, template)
+			return parser
+		},
+	}
+	return func(data interface{}) []interface{} {
+		parser := parsers.Get().(*jsonpath.JSONPath)
+		defer parsers.Put(parser)
+		results, err := parser.FindResults(data)
+		if err != nil {
+			return nil
+		}
+		var values []interface{}
+		for _, result := range results {
+			for _, value := range result {
+				values = append(values, value.Interface())
+			}
+		}
+		return values
+	}, nil
+}
+
+func parseJSONPath(name, template string) (*jsonpath.JSONPath, error) {
+	parser := jsonpath.New(name).AllowMissingKeys(true)
+	if err := parser.Parse(template); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
+
+// unstructuredContent returns the content of obj as unstructured data.
+func unstructuredContent(obj runtime.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// rowContentKey is the context key of the rowContentCache of a conversion.
+type rowContentKey struct{}
+
+// rowContentCache holds the unstructured content of the object of the row being converted, so that
+// typed objects are converted once per row rather than once per column.
+type rowContentCache struct {
+	obj       runtime.Object
+	converted bool
+	content   map[string]interface{}
+	err       error
+}
+
+// withRowContentCache returns a context carrying a cache for the content of the rows converted by
+// emitRows, which resets it for every row.
+func withRowContentCache(ctx context.Context) (context.Context, *rowContentCache) {
+	cache := &rowContentCache{}
+	return context.WithValue(ctx, rowContentKey{}, cache), cache
+}
+
+// reset makes the cache hold the content of obj, converted on first use.
+func (r *rowContentCache) reset(obj runtime.Object) {
+	*r = rowContentCache{obj: obj}
+}
+
+// rowContent returns the content of obj like unstructuredContent, from the cache of ctx if obj is
+// the object of the row being converted.
+func rowContent(ctx context.Context, obj runtime.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+	cache, ok := ctx.Value(rowContentKey{}).(*rowContentCache)
+	if !ok || cache.obj != obj {
+		return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	}
+	if !cache.converted {
+		cache.content, cache.err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		cache.converted = true
+	}
+	return cache.content, cache.err
+}
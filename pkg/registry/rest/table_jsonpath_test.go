@@ -0,0 +1,194 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	examplev1 "k8s.io/apiserver/pkg/apis/example/v1"
+)
+
+func newUnstructured(name string, fields map[string]interface{}) *unstructured.Unstructured {
+	content := map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": name},
+	}
+	for k, v := range fields {
+		content[k] = v
+	}
+	return &unstructured.Unstructured{Object: content}
+}
+
+func TestJSONPathTableConvertor(t *testing.T) {
+	c, err := NewJSONPathTableConvertor([]PrinterColumn{
+		{Name: "Name", Type: "string", Format: "name", JSONPath: ".metadata.name"},
+		{Name: "Replicas", Type: "integer", JSONPath: ".spec.replicas"},
+		{Name: "Ports", Type: "string", Priority: 1, JSONPath: ".spec.ports[*].port"},
+		{Name: "Port Numbers", Type: "array", Priority: 1, JSONPath: ".spec.ports[*].port"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+		*newUnstructured("a", map[string]interface{}{"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"ports":    []interface{}{map[string]interface{}{"port": int64(80)}, map[string]interface{}{"port": int64(443)}},
+		}}),
+		*newUnstructured("b", nil),
+	}}
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 4 || table.ColumnDefinitions[2].Priority != 1 || table.ColumnDefinitions[1].Type != "integer" {
+		t.Errorf("unexpected columns: %#v", table.ColumnDefinitions)
+	}
+	expected := [][]interface{}{
+		{"a", int64(3), "80,443", []interface{}{int64(80), int64(443)}},
+		{"b", nil, nil, nil},
+	}
+	for i, row := range table.Rows {
+		if !reflect.DeepEqual(expected[i], row.Cells) {
+			t.Errorf("row %d: expected cells %#v, got %#v", i, expected[i], row.Cells)
+		}
+	}
+
+	if _, err := NewJSONPathTableConvertor([]PrinterColumn{{Name: "Bad", Type: "string", JSONPath: ".spec[.bad"}}); err == nil {
+		t.Errorf("expected malformed expressions to fail at construction time")
+	}
+	if _, err := NewJSONPathTableConvertor([]PrinterColumn{{Name: "Replicas", Type: "int", JSONPath: ".spec.replicas"}}); err == nil {
+		t.Errorf("expected unknown column types to fail at construction time")
+	}
+}
+
+func TestRowContentCache(t *testing.T) {
+	pod := &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Spec: examplev1.PodSpec{NodeName: "node"}}
+	ctx, cache := withRowContentCache(context.TODO())
+	cache.reset(pod)
+	first, err := rowContent(ctx, pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := rowContent(ctx, pod)
+	if reflect.ValueOf(first).Pointer() != reflect.ValueOf(second).Pointer() {
+		t.Errorf("expected the content of the row to be converted once")
+	}
+	other := pod.DeepCopy()
+	if content, _ := rowContent(ctx, other); reflect.ValueOf(content).Pointer() == reflect.ValueOf(first).Pointer() {
+		t.Errorf("expected the content of another object to be converted")
+	}
+
+	c, err := NewJSONPathTableConvertor([]PrinterColumn{
+		{Name: "Name", Type: "string", JSONPath: ".metadata.name"},
+		{Name: "Node", Type: "string", JSONPath: ".spec.nodeName"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := c.ConvertToTable(context.TODO(), &examplev1.PodList{Items: []examplev1.Pod{*pod, {ObjectMeta: metav1.ObjectMeta{Name: "bar"}}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := [][]interface{}{{"foo", "node"}, {"bar", nil}}; !reflect.DeepEqual(expected, [][]interface{}{table.Rows[0].Cells, table.Rows[1].Cells}) {
+		t.Errorf("expected cells %#v, got %#v", expected, table.Rows)
+	}
+}
+
+func TestConvertToTableWithColumns(t *testing.T) {
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "widgets"}, WithExtraColumns(ExtraColumn{
+		Definition: metav1.TableColumnDefinition{Name: "Extra", Type: "string"},
+		Extract:    func(runtime.Object) (interface{}, error) { return "extra", nil },
+	})).(TableColumnsConvertor)
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+		*newUnstructured("foo", map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(2)}}),
+		*newUnstructured("bar", nil),
+	}}
+
+	columns := []PrinterColumn{
+		{Name: "Replicas", Type: "integer", JSONPath: ".spec.replicas"},
+		{Name: "Name", Type: "string", JSONPath: ".metadata.name"},
+	}
+	table, err := c.ConvertToTableWithColumns(context.TODO(), list, nil, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, def := range table.ColumnDefinitions {
+		names = append(names, def.Name)
+	}
+	if expected := []string{"Replicas", "Name"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected columns %v, got %v", expected, names)
+	}
+	expected := [][]interface{}{{int64(2), "foo"}, {nil, "bar"}}
+	for i, row := range table.Rows {
+		if !reflect.DeepEqual(expected[i], row.Cells) {
+			t.Errorf("%d: expected cells %#v, got %#v", i, expected[i], row.Cells)
+		}
+	}
+
+	table, err = c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 3 {
+		t.Errorf("expected the columns of the convertor to be unchanged, got %#v", table.ColumnDefinitions)
+	}
+
+	_, err = c.ConvertToTableWithColumns(context.TODO(), list, nil, []PrinterColumn{{Name: "Bad", Type: "string", JSONPath: ".spec[*"}})
+	if !apierrors.IsBadRequest(err) {
+		t.Errorf("expected a bad request error for a malformed JSONPath, got %v", err)
+	}
+	_, err = c.ConvertToTableWithColumns(context.TODO(), list, nil, []PrinterColumn{{Name: "Replicas", Type: "int", JSONPath: ".spec.replicas"}})
+	if !apierrors.IsBadRequest(err) {
+		t.Errorf("expected a bad request error for an unknown type, got %v", err)
+	}
+}
+
+func TestPrinterColumnNumberFormat(t *testing.T) {
+	columns := []PrinterColumn{
+		{Name: "Name", Type: "string", Format: "name", JSONPath: ".metadata.name"},
+		{Name: "Memory", Type: "string", JSONPath: ".spec.memory", Unit: "Mi", Thousands: true},
+		{Name: "Replicas", Type: "integer", JSONPath: ".spec.replicas", Thousands: true},
+	}
+	obj := newUnstructured("foo", map[string]interface{}{"spec": map[string]interface{}{"memory": int64(2048), "replicas": int64(1500)}})
+	for name, newConvertor := range map[string]func([]PrinterColumn) (TableConvertor, error){
+		"jsonpath": NewJSONPathTableConvertor,
+		"unstructured": func(columns []PrinterColumn) (TableConvertor, error) {
+			return NewUnstructuredTableConvertor(schema.GroupResource{Resource: "widgets"}, columns)
+		},
+	} {
+		c, err := newConvertor(columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		table, err := c.ConvertToTable(context.TODO(), obj, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := []interface{}{"foo", "2,048Mi", int64(1500)}; !reflect.DeepEqual(expected, table.Rows[0].Cells) {
+			t.Errorf("%s: expected cells %#v, got %#v", name, expected, table.Rows[0].Cells)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// MetricsRecorder observes the table conversions of a convertor, for instance to export their
+// latency and size as Prometheus metrics, without this package depending on a metrics library.
+type MetricsRecorder interface {
+	// ObserveConversion is called at the end of every successful conversion of the resource, with
+	// the number of rows it produced and the time it took. It is called concurrently by concurrent
+	// conversions.
+	ObserveConversion(resource schema.GroupResource, rows int, d time.Duration)
+}
+
+// WithMetricsRecorder reports every successful conversion of ConvertToTable, ConvertToTableChunk
+// and ConvertToTableStream to recorder. The resource is taken from the request info of the context
+// if set, and is the default resource of the convertor otherwise; the duration is measured with
+// the clock of the convertor. A nil recorder disables the reports.
+func WithMetricsRecorder(recorder MetricsRecorder) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.metrics = recorder
+	}
+}
+
+// observeConversion reports a conversion of the resource of the request in ctx that started at
+// start and produced rows rows, if the convertor has a metrics recorder.
+func (c *defaultTableConvertor) observeConversion(ctx context.Context, start time.Time, rows int) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.ObserveConversion(requestResource(ctx, c.defaultQualifiedResource), rows, c.clock.Since(start))
+}
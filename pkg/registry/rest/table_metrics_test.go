@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+type conversionObservation struct {
+	resource schema.GroupResource
+	rows     int
+	d        time.Duration
+}
+
+type fakeMetricsRecorder struct {
+	observations []conversionObservation
+}
+
+func (r *fakeMetricsRecorder) ObserveConversion(resource schema.GroupResource, rows int, d time.Duration) {
+	r.observations = append(r.observations, conversionObservation{resource: resource, rows: rows, d: d})
+}
+
+func TestMetricsRecorder(t *testing.T) {
+	clock := testingclock.NewFakeClock(testNow)
+	recorder := &fakeMetricsRecorder{}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithClock(clock), WithMetricsRecorder(recorder), WithFailOnExtraColumnError(), WithExtraColumns(ExtraColumn{
+		Definition: metav1.TableColumnDefinition{Name: "Slow", Type: "string"},
+		Extract: func(obj runtime.Object) (interface{}, error) {
+			clock.Step(time.Second)
+			if len(obj.(metav1.Object).GetName()) == 0 {
+				return nil, fmt.Errorf("unnamed")
+			}
+			return "", nil
+		},
+	}))
+	ctx := genericapirequest.WithRequestInfo(context.TODO(), &genericapirequest.RequestInfo{APIGroup: "example.com", Resource: "widgets"})
+
+	if _, err := c.ConvertToTable(context.TODO(), podListOf(3), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.(TableChunkConvertor).ConvertToTableChunk(ctx, podListOf(1), nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.(TableStreamConvertor).ConvertToTableStream(ctx, podListOf(2), nil, func(metav1.TableRow) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ConvertToTable(ctx, &metav1.Table{Rows: []metav1.TableRow{{}}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	list := podListOf(2)
+	list.Items[1].Name = ""
+	if _, err := c.ConvertToTable(ctx, list, nil); err == nil {
+		t.Fatal("expected an error for an unnamed pod")
+	}
+
+	widgets := schema.GroupResource{Group: "example.com", Resource: "widgets"}
+	expected := []conversionObservation{
+		{resource: schema.GroupResource{Resource: "pods"}, rows: 3, d: 3 * time.Second},
+		{resource: widgets, rows: 1, d: time.Second},
+		{resource: widgets, rows: 2, d: 2 * time.Second},
+		{resource: widgets, rows: 1},
+	}
+	if !reflect.DeepEqual(expected, recorder.observations) {
+		t.Errorf("expected observations %v, got %v", expected, recorder.observations)
+	}
+
+	if _, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithMetricsRecorder(nil)).ConvertToTable(ctx, podListOf(1), nil); err != nil {
+		t.Errorf("unexpected error without a recorder: %v", err)
+	}
+}
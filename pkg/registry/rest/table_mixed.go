@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// KindTable is the table of the objects of one kind of a mixed list.
+type KindTable struct {
+	// GroupKind is the kind of the objects of the table.
+	GroupKind schema.GroupKind
+	// Table holds a row per object of the kind, in list order.
+	Table *metav1.Table
+}
+
+// ConvertMixedListToTables converts list, whose items may be of different kinds like the
+// responses of "kubectl get all", to a table per kind, since a single set of columns cannot
+// describe all of them. The items are grouped by group and kind, ignoring the version, and every
+// group is converted with the convertor returned by convertorFor for its kind, or with a default
+// convertor if convertorFor returns nil. The tables are returned in the order of the first item of
+// each kind in list, and carry the list metadata of list.
+//
+// The kind of an item is read from its type metadata, or from typer for items whose type
+// metadata is empty, such as typed objects in memory; typer may be nil if all the items carry
+// their kind. An error is returned if the kind of an item cannot be determined or if any
+// conversion fails.
+func ConvertMixedListToTables(ctx context.Context, list runtime.Object, tableOptions runtime.Object, typer runtime.ObjectTyper, convertorFor func(schema.GroupKind) TableConvertor) ([]KindTable, error) {
+	var kinds []schema.GroupKind
+	groups := map[schema.GroupKind]*metav1.List{}
+	listMeta := metav1.ListMeta{}
+	if m, err := meta.ListAccessor(list); err == nil {
+		listMeta.ResourceVersion = m.GetResourceVersion()
+		listMeta.SelfLink = m.GetSelfLink()
+		listMeta.Continue = m.GetContinue()
+		listMeta.RemainingItemCount = m.GetRemainingItemCount()
+	}
+	i := 0
+	err := meta.EachListItem(list, func(obj runtime.Object) error {
+		gk, err := objectGroupKind(obj, typer)
+		if err != nil {
+			return fmt.Errorf("unable to determine the kind of item %d: %v", i, err)
+		}
+		i++
+		group, ok := groups[gk]
+		if !ok {
+			kinds = append(kinds, gk)
+			group = &metav1.List{ListMeta: listMeta}
+			groups[gk] = group
+		}
+		group.Items = append(group.Items, runtime.RawExtension{Object: obj})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]KindTable, 0, len(kinds))
+	for _, gk := range kinds {
+		var c TableConvertor
+		if convertorFor != nil {
+			c = convertorFor(gk)
+		}
+		if c == nil {
+			c = NewDefaultTableConvertor(schema.GroupResource{Group: gk.Group})
+		}
+		table, err := c.ConvertToTable(ctx, groups[gk], tableOptions)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert the objects of kind %s: %w", gk, err)
+		}
+		tables = append(tables, KindTable{GroupKind: gk, Table: table})
+	}
+	return tables, nil
+}
+
+// objectGroupKind returns the group and kind of obj, from its type metadata or from typer.
+func objectGroupKind(obj runtime.Object, typer runtime.ObjectTyper) (schema.GroupKind, error) {
+	if gvk := obj.GetObjectKind().GroupVersionKind(); len(gvk.Kind) > 0 {
+		return gvk.GroupKind(), nil
+	}
+	if typer == nil {
+		return schema.GroupKind{}, fmt.Errorf("%T has no kind", obj)
+	}
+	gvks, _, err := typer.ObjectKinds(obj)
+	if err != nil {
+		return schema.GroupKind{}, err
+	}
+	return gvks[0].GroupKind(), nil
+}
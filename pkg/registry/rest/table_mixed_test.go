@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
+)
+
+func TestConvertMixedListToTables(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := example.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	pod := func(name string) *example.Pod {
+		return &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	list := &metav1.List{
+		ListMeta: metav1.ListMeta{ResourceVersion: "10"},
+		Items: []runtime.RawExtension{
+			{Object: newUnstructured("w1", nil)},
+			{Object: pod("p1")},
+			{Object: newUnstructured("w2", nil)},
+			{Object: pod("p2")},
+		},
+	}
+	widgets := schema.GroupKind{Group: "example.com", Kind: "Widget"}
+	pods := schema.GroupKind{Group: example.GroupName, Kind: "Pod"}
+	convertorFor := func(gk schema.GroupKind) TableConvertor {
+		if gk == pods {
+			return NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithUIDColumn())
+		}
+		return nil
+	}
+
+	tables, err := ConvertMixedListToTables(context.TODO(), list, nil, scheme, convertorFor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || tables[0].GroupKind != widgets || tables[1].GroupKind != pods {
+		t.Fatalf("expected the widget and pod tables in list order, got %#v", tables)
+	}
+	for i, names := range [][]string{{"w1", "w2"}, {"p1", "p2"}} {
+		table := tables[i].Table
+		if table.ResourceVersion != "10" {
+			t.Errorf("%s: expected the resource version of the list, got %q", tables[i].GroupKind, table.ResourceVersion)
+		}
+		if len(table.Rows) != len(names) {
+			t.Fatalf("%s: expected %d rows, got %#v", tables[i].GroupKind, len(names), table.Rows)
+		}
+		for j, name := range names {
+			if table.Rows[j].Cells[0] != name {
+				t.Errorf("%s: expected row %d for %s, got %#v", tables[i].GroupKind, j, name, table.Rows[j].Cells)
+			}
+		}
+	}
+	if len(tables[0].Table.ColumnDefinitions) != 2 || len(tables[1].Table.ColumnDefinitions) != 3 {
+		t.Errorf("expected the default columns for widgets and the pod convertor columns for pods, got %#v and %#v", tables[0].Table.ColumnDefinitions, tables[1].Table.ColumnDefinitions)
+	}
+
+	if _, err := ConvertMixedListToTables(context.TODO(), list, nil, nil, convertorFor); err == nil {
+		t.Errorf("expected an error for items of unknown kind")
+	}
+}
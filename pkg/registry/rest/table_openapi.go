@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"strings"
+	"unicode"
+
+	"k8s.io/kube-openapi/pkg/util/proto"
+)
+
+// WithOpenAPIDescriptions describes the columns that have no description with the descriptions
+// of the fields of schema, the OpenAPI schema of the converted objects, so that clients and
+// discovery show meaningful text. The field of a column is found by its JSONPath for the columns
+// of printer columns, such as ".spec.replicas", and by its name otherwise, ignoring case, spaces
+// and punctuation, among the fields of the object, then of its spec, then of its status: a
+// "Node Name" column is described by the nodeName field of the spec if the object has no such
+// field. Columns whose field cannot be found keep an empty description. Descriptions set with
+// WithColumnDescriptions take precedence.
+func WithOpenAPIDescriptions(schema proto.Schema) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.openAPISchema = schema
+	}
+}
+
+// openAPIDescription returns the description of the field of col in schema, or an empty string.
+func openAPIDescription(schema proto.Schema, col column) string {
+	if len(col.jsonPath) > 0 {
+		fields, ok := jsonPathFields(col.jsonPath)
+		if !ok {
+			return ""
+		}
+		return schemaDescription(fieldSchema(schema, fields))
+	}
+	name := normalizeFieldName(col.definition.Name)
+	for _, parent := range [][]string{nil, {"spec"}, {"status"}} {
+		kind, ok := resolveSchema(fieldSchema(schema, parent)).(*proto.Kind)
+		if !ok {
+			continue
+		}
+		for _, field := range kind.Keys() {
+			if normalizeFieldName(field) == name {
+				return schemaDescription(kind.Fields[field])
+			}
+		}
+	}
+	return ""
+}
+
+// jsonPathFields returns the names of the fields selected by a JSONPath that only selects fields
+// and array items, like ".spec.ports[*].port".
+func jsonPathFields(path string) ([]string, bool) {
+	var fields []string
+	for _, field := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		if i := strings.Index(field, "["); i >= 0 {
+			if !strings.HasSuffix(field, "]") {
+				return nil, false
+			}
+			field = field[:i]
+		}
+		if len(field) == 0 || strings.ContainsAny(field, "[]{}()?@$,:'\"\\ ") {
+			return nil, false
+		}
+		fields = append(fields, field)
+	}
+	return fields, true
+}
+
+// fieldSchema returns the schema of the field at the path of fields in schema, looking through
+// references, arrays and maps, or nil if there is no such field.
+func fieldSchema(schema proto.Schema, fields []string) proto.Schema {
+	for _, field := range fields {
+		kind, ok := resolveSchema(schema).(*proto.Kind)
+		if !ok {
+			return nil
+		}
+		if schema, ok = kind.Fields[field]; !ok {
+			return nil
+		}
+	}
+	return schema
+}
+
+// resolveSchema returns the schema of the items of schema, if it is an array or a map, or the
+// schema schema refers to, if it is a reference.
+func resolveSchema(schema proto.Schema) proto.Schema {
+	for {
+		switch s := schema.(type) {
+		case proto.Reference:
+			schema = s.SubSchema()
+		case *proto.Array:
+			schema = s.SubType
+		case *proto.Map:
+			schema = s.SubType
+		default:
+			return schema
+		}
+	}
+}
+
+// schemaDescription returns the description of a field schema, or of the schema it refers to.
+func schemaDescription(schema proto.Schema) string {
+	if schema == nil {
+		return ""
+	}
+	if description := schema.GetDescription(); len(description) > 0 {
+		return description
+	}
+	if ref, ok := schema.(proto.Reference); ok && ref.SubSchema() != nil {
+		return ref.SubSchema().GetDescription()
+	}
+	return ""
+}
+
+// normalizeFieldName returns name in lower case without spaces and punctuation.
+func normalizeFieldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+)
+
+func described(description string) proto.BaseSchema {
+	return proto.BaseSchema{Description: description}
+}
+
+func TestWithOpenAPIDescriptions(t *testing.T) {
+	openAPISchema := &proto.Kind{Fields: map[string]proto.Schema{
+		"kind": &proto.Primitive{BaseSchema: described("Kind of the object."), Type: "string"},
+		"spec": &proto.Kind{BaseSchema: described("Spec of the widget."), Fields: map[string]proto.Schema{
+			"replicas": &proto.Primitive{BaseSchema: described("Number of desired replicas."), Type: "integer"},
+			"nodeName": &proto.Primitive{BaseSchema: described("Node the widget runs on."), Type: "string"},
+			"ports": &proto.Array{BaseSchema: described("Ports of the widget."), SubType: &proto.Kind{Fields: map[string]proto.Schema{
+				"port": &proto.Primitive{BaseSchema: described("Number of the port."), Type: "integer"},
+			}}},
+		}},
+		"status": &proto.Kind{Fields: map[string]proto.Schema{
+			"phase":    &proto.Primitive{BaseSchema: described("Phase of the widget."), Type: "string"},
+			"replicas": &proto.Primitive{BaseSchema: described("Number of observed replicas."), Type: "integer"},
+		}},
+	}}
+	extract := func(context.Context, runtime.Object) (interface{}, error) { return "", nil }
+	b := NewColumnBuilder(schema.GroupResource{Resource: "widgets"}).
+		AddColumn(metav1.TableColumnDefinition{Name: "Node Name", Type: "string"}, extract).
+		AddColumn(metav1.TableColumnDefinition{Name: "PHASE", Type: "string"}, extract).
+		AddColumn(metav1.TableColumnDefinition{Name: "Replicas", Type: "integer"}, extract).
+		AddColumn(metav1.TableColumnDefinition{Name: "Kind", Type: "string", Description: "Explicit."}, extract).
+		AddColumn(metav1.TableColumnDefinition{Name: "Unknown", Type: "string"}, extract).
+		AddPrinterColumns(
+			PrinterColumn{Name: "Desired", Type: "integer", JSONPath: ".spec.replicas"},
+			PrinterColumn{Name: "Ports", Type: "string", JSONPath: ".spec.ports[*].port"},
+			PrinterColumn{Name: "Missing", Type: "string", JSONPath: ".spec.missing"},
+			PrinterColumn{Name: "Filtered", Type: "string", JSONPath: ".spec.ports[?(@.port==80)].port"},
+		)
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+	descriptions := func(c TableConvertor) []string {
+		table, err := c.ConvertToTable(context.TODO(), newUnstructured("foo", nil), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var descriptions []string
+		for _, def := range table.ColumnDefinitions {
+			descriptions = append(descriptions, def.Description)
+		}
+		return descriptions
+	}
+
+	expected := []string{"Node the widget runs on.", "Phase of the widget.", "Number of desired replicas.", "Explicit.", "", "Number of desired replicas.", "Number of the port.", "", ""}
+	if got := descriptions(b.Build(WithOpenAPIDescriptions(openAPISchema))); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected descriptions %q, got %q", expected, got)
+	}
+	expected[0] = "Override."
+	if got := descriptions(b.Build(WithOpenAPIDescriptions(openAPISchema), WithColumnDescriptions(map[string]string{"Node Name": "Override."}))); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected descriptions %q, got %q", expected, got)
+	}
+	if got := descriptions(b.Build()); !reflect.DeepEqual([]string{"", "", "", "Explicit.", "", "", "", "", ""}, got) {
+		t.Errorf("expected no description without a schema, got %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// maxPooledRows is the capacity above which row slices are left to the garbage collector instead
+// of being pooled, so that a single huge list does not pin memory.
+const maxPooledRows = 4096
+
+var rowsPool = sync.Pool{
+	New: func() interface{} {
+		rows := make([]metav1.TableRow, 0, 64)
+		return &rows
+	},
+}
+
+// rowsHolders holds the empty pointers of the row slices taken from rowsPool, which releaseRows
+// reuses to return slices to the pool without allocating a pointer per release.
+var rowsHolders = sync.Pool{
+	New: func() interface{} {
+		return new([]metav1.TableRow)
+	},
+}
+
+// NewPooledTableConvertor creates a default convertor that takes the row slices of the tables it
+// returns from a pool. Servers converting lists at a high rate should return every table with
+// ReleaseTable once it has been encoded, which saves allocating and growing the slice of every
+// response. Tables that are not released are simply garbage collected.
+func NewPooledTableConvertor(defaultQualifiedResource schema.GroupResource, opts ...TableConvertorOption) TableConvertor {
+	// opts is copied so that appending never writes to the backing array of the caller
+	return NewDefaultTableConvertor(defaultQualifiedResource, append(append([]TableConvertorOption(nil), opts...), func(c *defaultTableConvertor) {
+		c.pooledRows = true
+	})...)
+}
+
+// ReleaseTable returns the rows of table, which must have been returned by a convertor created
+// with NewPooledTableConvertor, to the pool and removes them from table. Neither table nor its rows
+// may be used by the caller, or by anything the caller handed them to, after the call: rows are
+// reused by subsequent conversions.
+func ReleaseTable(table *metav1.Table) {
+	if table == nil {
+		return
+	}
+	releaseRows(table.Rows)
+	table.Rows = nil
+}
+
+// pooledRows returns an empty row slice from the pool.
+func pooledRows() []metav1.TableRow {
+	holder := rowsPool.Get().(*[]metav1.TableRow)
+	rows := *holder
+	*holder = nil
+	rowsHolders.Put(holder)
+	return rows
+}
+
+// releaseRows clears rows, so that the pool does not retain the objects they reference, and returns
+// them to the pool.
+func releaseRows(rows []metav1.TableRow) {
+	if cap(rows) == 0 || cap(rows) > maxPooledRows {
+		return
+	}
+	for i := range rows {
+		rows[i] = metav1.TableRow{}
+	}
+	holder := rowsHolders.Get().(*[]metav1.TableRow)
+	*holder = rows[:0]
+	rowsPool.Put(holder)
+}
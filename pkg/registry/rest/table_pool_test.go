@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
+)
+
+func podListOf(n int) *example.PodList {
+	list := &example.PodList{}
+	for i := 0; i < n; i++ {
+		list.Items = append(list.Items, example.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), CreationTimestamp: metav1.NewTime(testNow)}})
+	}
+	return list
+}
+
+func TestPooledTableConvertor(t *testing.T) {
+	pooled := NewPooledTableConvertor(schema.GroupResource{Resource: "pods"})
+	plain := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+
+	for i, n := range []int{3, 100, 1, 0} {
+		list := podListOf(n)
+		expected, err := plain.ConvertToTable(context.TODO(), list, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		table, err := pooled.ConvertToTable(context.TODO(), list, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected.Rows, table.Rows) {
+			t.Errorf("%d: expected rows %v, got %v", i, expected.Rows, table.Rows)
+		}
+		rows := table.Rows
+		ReleaseTable(table)
+		if table.Rows != nil {
+			t.Errorf("%d: expected the rows to be removed from the released table", i)
+		}
+		for j, row := range rows {
+			if row.Cells != nil || row.Object.Object != nil {
+				t.Errorf("%d: expected released row %d to be cleared, got %#v", i, j, row)
+			}
+		}
+	}
+
+	ReleaseTable(nil)
+	ReleaseTable(&metav1.Table{Rows: make([]metav1.TableRow, maxPooledRows+1)})
+
+	// the options of the caller are not written to, even with spare capacity
+	opts := make([]TableConvertorOption, 1, 2)
+	opts[0] = WithAgeColumn()
+	NewPooledTableConvertor(schema.GroupResource{Resource: "pods"}, opts...)
+	if opts[:2][1] != nil {
+		t.Errorf("expected the options of the caller to be left unmodified")
+	}
+}
+
+// BenchmarkPooledTableConvertor compares the bytes allocated by conversions with and without
+// pooled rows. Pooling saves the row slice of every conversion, about half of the bytes of a list
+// of pods, while the cells of the rows are allocated either way.
+func BenchmarkPooledTableConvertor(b *testing.B) {
+	list := podListOf(100)
+	ctx := context.TODO()
+	options := &metav1.TableOptions{IncludeObject: metav1.IncludeNone}
+
+	b.Run("default", func(b *testing.B) {
+		c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := c.ConvertToTable(ctx, list, options); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+	b.Run("pooled", func(b *testing.B) {
+		c := NewPooledTableConvertor(schema.GroupResource{Resource: "pods"})
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				table, err := c.ConvertToTable(ctx, list, options)
+				if err != nil {
+					b.Fatal(err)
+				}
+				ReleaseTable(table)
+			}
+		})
+	})
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// RedactedCell replaces the cells of the columns redacted by WithRedaction.
+const RedactedCell = "***"
+
+// redaction hides the cells of columns from the requests that are not allowed to see them.
+type redaction struct {
+	allowed func(ctx context.Context) bool
+	columns sets.String
+}
+
+// WithRedaction replaces the cells of the columns with the given names by RedactedCell for the
+// requests for which allowed returns false, for instance based on the user of the request in ctx,
+// so that every caller sees the structure of the table while only some see sensitive values. The
+// columns are still rendered with their definitions. Label columns requested by clients are
+// redacted if their label key is among the names. allowed is called once per conversion, with
+// the context passed to ConvertToTable. The option can be repeated to protect columns with
+// different predicates.
+//
+// Only the cells are redacted: the objects embedded in rows still carry the values. Objects
+// embedded with the Object policy carry all of them, and the metadata embedded with the default
+// Metadata policy carries the labels and annotations, so convertors redacting such values should
+// also be built with WithNeverIncludeObject. Tables that were already converted are redacted by
+// column name; all the cells of those without column definitions are redacted.
+func WithRedaction(allowed func(ctx context.Context) bool, columns ...string) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.redactions = append(c.redactions, redaction{allowed: allowed, columns: sets.NewString(columns...)})
+	}
+}
+
+// redactedColumns returns the names of the columns that the request in ctx is not allowed to see.
+func (c *defaultTableConvertor) redactedColumns(ctx context.Context) sets.String {
+	redacted := sets.NewString()
+	for _, r := range c.redactions {
+		if !r.allowed(ctx) {
+			redacted = redacted.Union(r.columns)
+		}
+	}
+	return redacted
+}
+
+// redacts reports whether the column with the given name is redacted for some requests.
+func (c *defaultTableConvertor) redacts(name string) bool {
+	for _, r := range c.redactions {
+		if r.columns.Has(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactColumns returns columns with the cells of the columns that the request in ctx is not
+// allowed to see replaced by RedactedCell. columns is not modified.
+func (c *defaultTableConvertor) redactColumns(ctx context.Context, columns []column) []column {
+	redacted := c.redactedColumns(ctx)
+	if redacted.Len() == 0 {
+		return columns
+	}
+	result := make([]column, len(columns))
+	for i, col := range columns {
+		if redacted.Has(col.definition.Name) {
+			col.cell = redactedCell
+			col.redacted = true
+		}
+		result[i] = col
+	}
+	return result
+}
+
+// redactRows replaces, in rows of a table that was already converted with the given column
+// definitions, the cells of the columns that the request in ctx is not allowed to see by
+// RedactedCell. The cells of rows are copied before being redacted. Without definitions, the
+// columns cannot be told apart and all the cells are redacted.
+func (c *defaultTableConvertor) redactRows(ctx context.Context, definitions []metav1.TableColumnDefinition, rows []metav1.TableRow) {
+	if len(c.redactions) == 0 {
+		return
+	}
+	redacted := c.redactedColumns(ctx)
+	if redacted.Len() == 0 {
+		return
+	}
+	for i := range rows {
+		cells := make([]interface{}, len(rows[i].Cells))
+		for j, cell := range rows[i].Cells {
+			if len(definitions) == 0 || (j < len(definitions) && redacted.Has(definitions[j].Name)) {
+				cell = RedactedCell
+			}
+			cells[j] = cell
+		}
+		rows[i].Cells = cells
+	}
+}
+
+func redactedCell(context.Context, runtime.Object, metav1.Object) (interface{}, error) {
+	return RedactedCell, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	examplev1 "k8s.io/apiserver/pkg/apis/example/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestWithRedaction(t *testing.T) {
+	calls := 0
+	admin := func(ctx context.Context) bool {
+		calls++
+		u, ok := genericapirequest.UserFrom(ctx)
+		return ok && sets.NewString(u.GetGroups()...).Has(user.SystemPrivilegedGroup)
+	}
+	list := &examplev1.PodList{Items: []examplev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "uid-foo", Labels: map[string]string{"owner": "alice"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "bar", UID: "uid-bar", Labels: map[string]string{"owner": "bob"}}},
+	}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithUIDColumn(), WithRedaction(admin, "UID", "owner"))
+	options := &ExtendedTableOptions{LabelColumns: []string{"owner"}}
+
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		options  *ExtendedTableOptions
+		expected [][]interface{}
+	}{
+		{
+			name:     "anonymous",
+			ctx:      context.TODO(),
+			expected: [][]interface{}{{"foo", RedactedCell}, {"bar", RedactedCell}},
+		},
+		{
+			name:     "user with labels",
+			ctx:      genericapirequest.WithUser(context.TODO(), &user.DefaultInfo{Name: "alice"}),
+			options:  options,
+			expected: [][]interface{}{{"foo", RedactedCell, RedactedCell}, {"bar", RedactedCell, RedactedCell}},
+		},
+		{
+			name:     "admin",
+			ctx:      genericapirequest.WithUser(context.TODO(), &user.DefaultInfo{Name: "admin", Groups: []string{user.SystemPrivilegedGroup}}),
+			options:  options,
+			expected: [][]interface{}{{"foo", "uid-foo", "alice"}, {"bar", "uid-bar", "bob"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = 0
+			var tableOptions runtime.Object
+			if tc.options != nil {
+				tableOptions = tc.options
+			}
+			table, err := c.ConvertToTable(tc.ctx, list, tableOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(table.ColumnDefinitions) != len(tc.expected[0])+1 || table.ColumnDefinitions[2].Name != "UID" {
+				t.Errorf("expected the redacted columns to keep their definitions, got %#v", table.ColumnDefinitions)
+			}
+			var cells [][]interface{}
+			for _, row := range table.Rows {
+				cells = append(cells, append([]interface{}{row.Cells[0]}, row.Cells[2:]...))
+			}
+			if !reflect.DeepEqual(tc.expected, cells) {
+				t.Errorf("expected cells %#v, got %#v", tc.expected, cells)
+			}
+			if calls != 1 {
+				t.Errorf("expected the predicate to be called once per conversion, got %d calls", calls)
+			}
+		})
+	}
+}
+
+func TestWithRedactionPassthrough(t *testing.T) {
+	admin := genericapirequest.WithUser(context.TODO(), &user.DefaultInfo{Name: "admin", Groups: []string{user.SystemPrivilegedGroup}})
+	allowed := func(ctx context.Context) bool {
+		u, ok := genericapirequest.UserFrom(ctx)
+		return ok && sets.NewString(u.GetGroups()...).Has(user.SystemPrivilegedGroup)
+	}
+	pod := &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "uid-foo"}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithUIDColumn(), WithRedaction(allowed, "UID"))
+	cached, err := c.ConvertToTable(admin, pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tables that were already converted, for instance by a cache, are redacted by column name
+	table, err := c.ConvertToTable(context.TODO(), cached, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cells := table.Rows[0].Cells; cells[0] != "foo" || cells[2] != RedactedCell {
+		t.Errorf("expected the UID cell to be redacted, got %#v", cells)
+	}
+	if cached.Rows[0].Cells[2] != "uid-foo" {
+		t.Errorf("expected the cells of the passed table to be left unmodified, got %#v", cached.Rows[0].Cells)
+	}
+	if table, err = c.ConvertToTable(admin, cached, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cells := table.Rows[0].Cells; cells[2] != "uid-foo" {
+		t.Errorf("expected the UID cell to be shown to allowed requests, got %#v", cells)
+	}
+
+	// without column definitions, every cell is redacted
+	headless, err := c.ConvertToTable(admin, pod, &metav1.TableOptions{NoHeaders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table, err = c.ConvertToTable(context.TODO(), headless, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, cell := range table.Rows[0].Cells {
+		if cell != RedactedCell {
+			t.Errorf("expected every cell of a table without definitions to be redacted, got %#v", table.Rows[0].Cells)
+			break
+		}
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TableConvertorRegistry maps resources to the convertors of their tables, for handlers serving
+// several resources. It is safe for concurrent use: resources are typically registered at
+// initialization and looked up at request time.
+type TableConvertorRegistry struct {
+	lock       sync.RWMutex
+	convertors map[schema.GroupResource]TableConvertor
+	// defaults are the default convertors returned for the resources without a registered
+	// convertor, created on first use.
+	defaults map[schema.GroupResource]TableConvertor
+}
+
+// NewTableConvertorRegistry returns an empty registry.
+func NewTableConvertorRegistry() *TableConvertorRegistry {
+	return &TableConvertorRegistry{
+		convertors: map[schema.GroupResource]TableConvertor{},
+		defaults:   map[schema.GroupResource]TableConvertor{},
+	}
+}
+
+// Register sets the convertor of the tables of gr, replacing any convertor registered before.
+func (r *TableConvertorRegistry) Register(gr schema.GroupResource, c TableConvertor) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.convertors[gr] = c
+}
+
+// ConvertorFor returns the convertor registered for gr and true, or a default convertor for gr,
+// as returned by NewDefaultTableConvertor, and false if none is registered. The default convertor
+// of gr is created once and shared by the lookups of gr.
+func (r *TableConvertorRegistry) ConvertorFor(gr schema.GroupResource) (TableConvertor, bool) {
+	r.lock.RLock()
+	c, ok := r.convertors[gr]
+	if !ok {
+		c = r.defaults[gr]
+	}
+	r.lock.RUnlock()
+	if ok {
+		return c, true
+	}
+	if c != nil {
+		return c, false
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if c, ok := r.convertors[gr]; ok {
+		return c, true
+	}
+	if c, ok := r.defaults[gr]; ok {
+		return c, false
+	}
+	c = NewDefaultTableConvertor(gr)
+	r.defaults[gr] = c
+	return c, false
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
+)
+
+func TestTableConvertorRegistry(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+	widgets := schema.GroupResource{Group: "example.com", Resource: "widgets"}
+	r := NewTableConvertorRegistry()
+	podConvertor := NewDefaultTableConvertor(pods, WithUIDColumn())
+	r.Register(pods, podConvertor)
+
+	if c, ok := r.ConvertorFor(pods); !ok || c != podConvertor {
+		t.Errorf("expected the registered convertor for pods, got %v, %v", c, ok)
+	}
+	c, ok := r.ConvertorFor(widgets)
+	if ok {
+		t.Errorf("expected no convertor to be registered for widgets")
+	}
+	_, err := c.ConvertToTable(context.TODO(), &metav1.Status{}, nil)
+	if resource, ok := IsNotAcceptable(err); !ok || resource != widgets {
+		t.Errorf("expected the default convertor for widgets, got %v", err)
+	}
+	if again, _ := r.ConvertorFor(widgets); again != c {
+		t.Errorf("expected the default convertor for widgets to be shared")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r.Register(widgets, podConvertor)
+		}()
+		go func() {
+			defer wg.Done()
+			c, _ := r.ConvertorFor(pods)
+			if _, err := c.ConvertToTable(context.TODO(), &example.Pod{}, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if c, ok := r.ConvertorFor(widgets); !ok || c != podConvertor {
+		t.Errorf("expected the convertor registered concurrently for widgets, got %v, %v", c, ok)
+	}
+}
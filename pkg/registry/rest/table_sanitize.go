@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithSanitizedStrings escapes the non-printable characters of string cells, so that object
+// content, like names or labels, cannot inject control characters or terminal escape sequences in
+// the output of clients. Every rune that is neither a space nor printable according to
+// unicode.IsPrint, which covers control characters like newlines, tabs and ESC as well as
+// formatting characters like bidirectional overrides, is replaced by its Go escape sequence, for
+// example "\n", "\x1b" or "\u202e", and every invalid UTF-8 byte by "\x" and its hexadecimal
+// value. Backslashes are left unchanged.
+//
+// String cells and the string elements of slice cells are sanitized; the embedded objects of rows
+// are not.
+func WithSanitizedStrings() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.sanitizeStrings = true
+	}
+}
+
+// sanitizeCell returns cell with its strings sanitized by sanitizeString.
+func sanitizeCell(cell interface{}) interface{} {
+	switch v := cell.(type) {
+	case string:
+		return sanitizeString(v)
+	case []string:
+		sanitized := make([]string, len(v))
+		for i, s := range v {
+			sanitized[i] = sanitizeString(s)
+		}
+		return sanitized
+	case []interface{}:
+		sanitized := make([]interface{}, len(v))
+		for i, item := range v {
+			if s, ok := item.(string); ok {
+				item = sanitizeString(s)
+			}
+			sanitized[i] = item
+		}
+		return sanitized
+	}
+	return cell
+}
+
+// sanitizeString escapes the non-printable runes and the invalid bytes of s.
+func sanitizeString(s string) string {
+	if isPrintable(s) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == ' ' || unicode.IsPrint(r):
+			b.WriteRune(r)
+		default:
+			quoted := strconv.QuoteRune(r)
+			b.WriteString(quoted[1 : len(quoted)-1])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// isPrintable reports whether s is valid UTF-8 made of spaces and printable runes only.
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if r == utf8.RuneError || (r != ' ' && !unicode.IsPrint(r)) {
+			return false
+		}
+	}
+	return true
+}
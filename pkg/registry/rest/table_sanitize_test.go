@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
+)
+
+func TestSanitizeString(t *testing.T) {
+	tests := map[string]string{
+		"plain name":                  "plain name",
+		"bad\nname":                   `bad\nname`,
+		"\x1b[31mred\x1b[0m":          `\x1b[31mred\x1b[0m`,
+		"tab\tand\rreturn":            `tab\tand\rreturn`,
+		"bell\a":                      `bell\a`,
+		"override\u202eevil":          `override\u202eevil`,
+		"invalid\xffbyte":             `invalid\xffbyte`,
+		"ünïcödé, 名前 and back\\slash": "ünïcödé, 名前 and back\\slash",
+	}
+	for in, expected := range tests {
+		if got := sanitizeString(in); got != expected {
+			t.Errorf("%q: expected %q, got %q", in, expected, got)
+		}
+	}
+}
+
+func TestWithSanitizedStrings(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "evil\n\x1b[2Jname"}}
+	extra := WithExtraColumns(ExtraColumn{
+		Definition: metav1.TableColumnDefinition{Name: "Tags", Type: "string"},
+		Extract: func(runtime.Object) (interface{}, error) {
+			return []interface{}{"a\nb", int64(1)}, nil
+		},
+	})
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, extra, WithSanitizedStrings())
+	table, err := c.ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := table.Rows[0].Cells[0]; name != `evil\n\x1b[2Jname` {
+		t.Errorf("expected the name to be escaped, got %q", name)
+	}
+	if tags := table.Rows[0].Cells[2]; !reflect.DeepEqual(tags, []interface{}{`a\nb`, int64(1)}) {
+		t.Errorf("expected the string items to be escaped, got %#v", tags)
+	}
+
+	table, err = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := table.Rows[0].Cells[0]; name != pod.Name {
+		t.Errorf("expected the name to be unchanged without the option, got %q", name)
+	}
+}
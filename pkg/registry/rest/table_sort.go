@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SortByColumn orders the rows of converted tables by the cells of the column with the given name.
+// Cells of "integer" and "number" columns are compared numerically, other cells as strings, and
+// empty (nil) cells come first in ascending order. Rows with equal cells are ordered by the cells of
+// the columns with the "name" format, in column and ascending order, and otherwise keep the order
+// of the list. Tables are left in list order if the column is not rendered, and the rows passed to
+// the emit function of ConvertToTableStream are never sorted.
+//
+// Only the rows of a single conversion are sorted: for a paginated list, sorting applies within
+// each page, not across the complete list.
+func SortByColumn(name string, ascending bool) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.sortColumn = name
+		c.sortAscending = ascending
+	}
+}
+
+// sortRows orders rows by the cells of the sort column of c among definitions.
+func (c *defaultTableConvertor) sortRows(definitions []metav1.TableColumnDefinition, rows []metav1.TableRow) {
+	sortIndex := -1
+	var nameIndexes []int
+	for i, def := range definitions {
+		if def.Name == c.sortColumn && sortIndex < 0 {
+			sortIndex = i
+		}
+		if def.Format == "name" {
+			nameIndexes = append(nameIndexes, i)
+		}
+	}
+	if sortIndex < 0 {
+		return
+	}
+	colType := definitions[sortIndex].Type
+	sort.SliceStable(rows, func(i, j int) bool {
+		cmp := compareCells(colType, cellAt(rows[i], sortIndex), cellAt(rows[j], sortIndex))
+		if !c.sortAscending {
+			cmp = -cmp
+		}
+		for _, nameIndex := range nameIndexes {
+			if cmp != 0 {
+				break
+			}
+			cmp = compareCells("string", cellAt(rows[i], nameIndex), cellAt(rows[j], nameIndex))
+		}
+		return cmp < 0
+	})
+}
+
+// cellAt returns the cell of row at index, or nil if row has no such cell.
+func cellAt(row metav1.TableRow, index int) interface{} {
+	if index < len(row.Cells) {
+		return row.Cells[index]
+	}
+	return nil
+}
+
+// compareCells returns -1, 0 or 1 depending on whether a sorts before, with or after b in a column
+// of the given type.
+func compareCells(colType string, a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if colType == "integer" || colType == "number" {
+		x, xok := toNumber(a)
+		y, yok := toNumber(b)
+		if xok && yok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	if i, ok := toInt64(value); ok {
+		return float64(i), true
+	}
+	return toFloat64(value)
+}
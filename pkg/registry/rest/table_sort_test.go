@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
+)
+
+func TestSortByColumn(t *testing.T) {
+	list := &example.PodList{Items: []example.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "c"}, Spec: example.PodSpec{NodeName: "node1", ActiveDeadlineSeconds: int64Ptr(10)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Spec: example.PodSpec{NodeName: "node2", ActiveDeadlineSeconds: int64Ptr(9)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: example.PodSpec{NodeName: "node1", ActiveDeadlineSeconds: int64Ptr(100)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "d"}},
+	}}
+	deadline := func(obj runtime.Object) (interface{}, error) {
+		if p := obj.(*example.Pod).Spec.ActiveDeadlineSeconds; p != nil {
+			return *p, nil
+		}
+		return nil, nil
+	}
+	extras := WithExtraColumns(
+		ExtraColumn{Definition: metav1.TableColumnDefinition{Name: "Node", Type: "string"}, Extract: podNodeName},
+		ExtraColumn{Definition: metav1.TableColumnDefinition{Name: "Deadline", Type: "integer"}, Extract: deadline},
+	)
+
+	testCases := []struct {
+		name      string
+		column    string
+		ascending bool
+		expected  []string
+	}{
+		{name: "ascending strings", column: "Name", ascending: true, expected: []string{"a", "b", "c", "d"}},
+		{name: "descending strings", column: "Name", expected: []string{"d", "c", "b", "a"}},
+		{name: "numbers", column: "Deadline", ascending: true, expected: []string{"d", "a", "c", "b"}},
+		{name: "descending numbers", column: "Deadline", expected: []string{"b", "c", "a", "d"}},
+		{name: "ties ordered by name", column: "Node", ascending: true, expected: []string{"d", "b", "c", "a"}},
+		{name: "descending ties ordered by name", column: "Node", expected: []string{"a", "b", "c", "d"}},
+		{name: "unknown column", column: "Unknown", ascending: true, expected: []string{"c", "a", "b", "d"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, extras, SortByColumn(tc.column, tc.ascending))
+			table, err := c.ConvertToTable(context.TODO(), list, &metav1.TableOptions{NoHeaders: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, row := range table.Rows {
+				names = append(names, row.Cells[0].(string))
+			}
+			if !reflect.DeepEqual(tc.expected, names) {
+				t.Errorf("expected %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func TestSortByColumnCompositeName(t *testing.T) {
+	list := &example.PodList{Items: []example.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "b", Name: "x"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "y"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "x"}},
+	}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithNamespaceColumn(), WithCompositeName("Namespace", "Name"), SortByColumn("Created At", true))
+	table, err := c.ConvertToTable(context.TODO(), list, &metav1.TableOptions{NoHeaders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, row := range table.Rows {
+		keys = append(keys, row.Cells[0].(string)+"/"+row.Cells[1].(string))
+	}
+	if expected := []string{"a/x", "a/y", "b/x"}; !reflect.DeepEqual(expected, keys) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+}
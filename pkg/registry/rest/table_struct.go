@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	metav1TimeType = reflect.TypeOf(metav1.Time{})
+)
+
+// ColumnsFromStruct returns the column definitions declared by the table tags of the fields of
+// obj, a struct or a pointer to a struct, in field order. A tag has the form
+//
+//	table:"Name,format=name,priority=0,type=string,description=The name of the object."
+//
+// where the first element is the column name and the other elements are optional. The description,
+// which may contain commas, must be the last element. The type must be valid according to
+// ValidColumnType; unless given, it is derived from the Go type of the field: "string", "integer", "number", "boolean" or,
+// for time.Time and metav1.Time, "date". Fields tagged "-" and untagged fields are skipped, and
+// the fields of untagged embedded structs are inspected in place, once per struct type so that
+// structs embedding pointers to themselves are supported. An error is returned for tags
+// on unexported fields, malformed tags and fields whose type cannot be derived.
+func ColumnsFromStruct(obj interface{}) ([]metav1.TableColumnDefinition, error) {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct or a pointer to a struct, got %T", obj)
+	}
+	return structColumns(t, map[reflect.Type]bool{t: true})
+}
+
+// structColumns returns the columns of the fields of t, skipping the embedded structs of visited
+// types.
+func structColumns(t reflect.Type, visited map[reflect.Type]bool) ([]metav1.TableColumnDefinition, error) {
+	var columns []metav1.TableColumnDefinition
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("table")
+		if !ok {
+			if field.Anonymous {
+				embedded := field.Type
+				if embedded.Kind() == reflect.Ptr {
+					embedded = embedded.Elem()
+				}
+				if embedded.Kind() == reflect.Struct && !visited[embedded] {
+					visited[embedded] = true
+					nested, err := structColumns(embedded, visited)
+					if err != nil {
+						return nil, err
+					}
+					columns = append(columns, nested...)
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			return nil, fmt.Errorf("field %s.%s: table tags are not allowed on unexported fields", t.Name(), field.Name)
+		}
+		column, err := tagColumn(field, tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s.%s: %v", t.Name(), field.Name, err)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// tagColumn returns the column definition declared by the table tag of field.
+func tagColumn(field reflect.StructField, tag string) (metav1.TableColumnDefinition, error) {
+	parts := strings.Split(tag, ",")
+	column := metav1.TableColumnDefinition{Name: strings.TrimSpace(parts[0])}
+	if len(column.Name) == 0 {
+		return column, fmt.Errorf("table tag %q has no column name", tag)
+	}
+	for i, part := range parts[1:] {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return column, fmt.Errorf("table tag %q: expected key=value, got %q", tag, part)
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch key {
+		case "format":
+			column.Format = value
+		case "type":
+			if !ValidColumnType(value) {
+				return column, fmt.Errorf("table tag %q: unknown type %q, the types are %q", tag, value, validColumnTypes)
+			}
+			column.Type = value
+		case "description":
+			// the description extends to the end of the tag
+			column.Description = strings.TrimSpace(strings.SplitN(strings.Join(parts[i+1:], ","), "=", 2)[1])
+			return column, deriveType(field, &column)
+		case "priority":
+			priority, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || priority < 0 {
+				return column, fmt.Errorf("table tag %q: priority must be a non-negative integer, got %q", tag, value)
+			}
+			column.Priority = int32(priority)
+		default:
+			return column, fmt.Errorf("table tag %q: unknown key %q", tag, key)
+		}
+	}
+	return column, deriveType(field, &column)
+}
+
+// deriveType sets the type of column from the Go type of field, if the tag does not set it.
+func deriveType(field reflect.StructField, column *metav1.TableColumnDefinition) error {
+	if len(column.Type) > 0 {
+		return nil
+	}
+	colType, ok := columnType(field.Type)
+	if !ok {
+		return fmt.Errorf("unable to derive the column type of %s, set it with type=", field.Type)
+	}
+	column.Type = colType
+	return nil
+}
+
+// columnType returns the OpenAPI type of a column rendering values of type t.
+func columnType(t reflect.Type) (string, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || t == metav1TimeType {
+		return "date", true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string", true
+	case reflect.Bool:
+		return "boolean", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer", true
+	case reflect.Float32, reflect.Float64:
+		return "number", true
+	}
+	return "", false
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type widgetColumns struct {
+	Name string `table:"Name,format=name,description=The name, unique per namespace, of the widget."`
+	widgetStatus
+	// embedding a pointer to the struct itself contributes no columns
+	*widgetColumns
+	Internal string
+	Ignored  string `table:"-"`
+}
+
+type widgetStatus struct {
+	Replicas int32                `table:"Replicas"`
+	Ready    *bool                `table:"Ready,priority=1"`
+	Ratio    float64              `table:"Ratio,priority=1"`
+	Created  metav1.Time          `table:"Created At"`
+	Selector metav1.LabelSelector `table:"Selector,type=string"`
+}
+
+func TestColumnsFromStruct(t *testing.T) {
+	expected := []metav1.TableColumnDefinition{
+		{Name: "Name", Type: "string", Format: "name", Description: "The name, unique per namespace, of the widget."},
+		{Name: "Replicas", Type: "integer"},
+		{Name: "Ready", Type: "boolean", Priority: 1},
+		{Name: "Ratio", Type: "number", Priority: 1},
+		{Name: "Created At", Type: "date"},
+		{Name: "Selector", Type: "string"},
+	}
+	for _, obj := range []interface{}{widgetColumns{}, &widgetColumns{}} {
+		columns, err := ColumnsFromStruct(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, columns) {
+			t.Errorf("%T: expected %#v, got %#v", obj, expected, columns)
+		}
+	}
+}
+
+func TestColumnsFromStructErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		obj      interface{}
+		expected string
+	}{
+		{name: "not a struct", obj: "widget", expected: "expected a struct"},
+		{name: "nil", obj: nil, expected: "expected a struct"},
+		{name: "unexported", obj: struct {
+			name string `table:"Name"`
+		}{}, expected: "unexported"},
+		{name: "missing name", obj: struct {
+			Name string `table:",format=name"`
+		}{}, expected: "no column name"},
+		{name: "malformed option", obj: struct {
+			Name string `table:"Name,name"`
+		}{}, expected: "expected key=value"},
+		{name: "unknown key", obj: struct {
+			Name string `table:"Name,width=10"`
+		}{}, expected: `unknown key "width"`},
+		{name: "invalid priority", obj: struct {
+			Name string `table:"Name,priority=high"`
+		}{}, expected: "priority must be a non-negative integer"},
+		{name: "invalid type", obj: struct {
+			Name string `table:"Name,type=text"`
+		}{}, expected: `unknown type "text"`},
+		{name: "underived type", obj: struct {
+			Labels map[string]string `table:"Labels"`
+		}{}, expected: "unable to derive the column type"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ColumnsFromStruct(tc.obj)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected an error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/clock"
+)
+
+// NewScaleTableConvertor creates a convertor for the responses of scale subresources, like
+// autoscaling/v1 Scale objects, rendering their name and their desired and current replicas from
+// spec.replicas and status.replicas. Missing replicas are rendered as zero, since the fields are
+// omitted when empty. The options apply as for NewDefaultTableConvertor, except for the options of
+// default columns that scale tables do not have: WithNamespaceColumn, WithAgeColumn,
+// WithAgeFormat and WithDefaultColumnsWarning have no effect.
+func NewScaleTableConvertor(opts ...TableConvertorOption) TableConvertor {
+	c := &defaultTableConvertor{
+		defaultQualifiedResource: schema.GroupResource{Group: "autoscaling", Resource: "scale"},
+		clock:                    clock.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.setColumns([]column{
+		{
+			definition: DefaultColumns()[0],
+			cell: func(_ context.Context, obj runtime.Object, m metav1.Object) (interface{}, error) {
+				if c.nameFunc != nil {
+					return c.nameFunc(obj), nil
+				}
+				return m.GetName(), nil
+			},
+		},
+		replicasColumn("Desired", "The desired number of replicas.", "spec"),
+		replicasColumn("Current", "The current number of replicas.", "status"),
+	})
+	c.setVersionedColumns()
+	return c
+}
+
+// replicasColumn returns an integer column rendering the replicas field of the given top-level
+// field of scale objects.
+func replicasColumn(name, description, field string) column {
+	return column{
+		definition: metav1.TableColumnDefinition{Name: name, Type: "integer", Description: description},
+		jsonPath:   "." + field + ".replicas",
+		cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+			content, err := rowContent(ctx, obj)
+			if err != nil {
+				return nil, err
+			}
+			replicas, _ := nestedInt64(content, field, "replicas")
+			return replicas, nil
+		},
+	}
+}
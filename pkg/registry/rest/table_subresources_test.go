@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestScaleTableConvertor(t *testing.T) {
+	scale := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v1",
+		"kind":       "Scale",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec":       map[string]interface{}{"replicas": int64(5)},
+		"status":     map[string]interface{}{"replicas": int64(3), "selector": "app=web"},
+	}}
+	table, err := NewScaleTableConvertor().ConvertToTable(context.TODO(), scale, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, def := range table.ColumnDefinitions {
+		names = append(names, def.Name)
+	}
+	if expected := []string{"Name", "Desired", "Current"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected columns %q, got %q", expected, names)
+	}
+	if def := table.ColumnDefinitions[1]; def.Type != "integer" {
+		t.Errorf("expected an integer column, got %#v", def)
+	}
+	if expected := []interface{}{"web", int64(5), int64(3)}; !reflect.DeepEqual(expected, table.Rows[0].Cells) {
+		t.Errorf("expected cells %#v, got %#v", expected, table.Rows[0].Cells)
+	}
+
+	// scaled to zero, spec.replicas and status.replicas are omitted
+	unstructured.RemoveNestedField(scale.Object, "spec", "replicas")
+	unstructured.RemoveNestedField(scale.Object, "status", "replicas")
+	if table, err = NewScaleTableConvertor().ConvertToTable(context.TODO(), scale, nil); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []interface{}{"web", int64(0), int64(0)}; !reflect.DeepEqual(expected, table.Rows[0].Cells) {
+		t.Errorf("expected cells %#v, got %#v", expected, table.Rows[0].Cells)
+	}
+	named := NewScaleTableConvertor(WithNameFunc(func(obj runtime.Object) string {
+		return "scale/" + obj.(*unstructured.Unstructured).GetName()
+	}))
+	if table, err = named.ConvertToTable(context.TODO(), scale, nil); err != nil {
+		t.Fatal(err)
+	}
+	if name := table.Rows[0].Cells[0]; name != "scale/web" {
+		t.Errorf("expected the name of the name func, got %#v", name)
+	}
+
+	versioned := NewScaleTableConvertor(WithVersionedColumns(map[schema.GroupVersion][]PrinterColumn{
+		{Group: "autoscaling", Version: "v1"}: {{Name: "Selector", Type: "string", JSONPath: ".status.selector"}},
+	}))
+	ctx := genericapirequest.WithRequestInfo(context.TODO(), &genericapirequest.RequestInfo{APIGroup: "autoscaling", APIVersion: "v1", Resource: "deployments", Subresource: "scale"})
+	if table, err = versioned.ConvertToTable(ctx, scale, nil); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []interface{}{"app=web"}; !reflect.DeepEqual(expected, table.Rows[0].Cells) {
+		t.Errorf("expected the versioned cells %#v, got %#v", expected, table.Rows[0].Cells)
+	}
+}
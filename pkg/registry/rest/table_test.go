@@ -0,0 +1,1621 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apiserver/pkg/apis/example"
+	"k8s.io/apiserver/pkg/apis/example/install"
+	examplev1 "k8s.io/apiserver/pkg/apis/example/v1"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/warning"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+var testNow = time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+func TestDefaultTableConvertorAge(t *testing.T) {
+	tests := []struct {
+		name    string
+		created time.Time
+		want    string
+	}{
+		{name: "zero timestamp", want: "<unknown>"},
+		{name: "seconds", created: testNow.Add(-30 * time.Second), want: "30s"},
+		{name: "minutes", created: testNow.Add(-5 * time.Minute), want: "5m"},
+		{name: "days", created: testNow.Add(-3 * 24 * time.Hour), want: "3d"},
+		{name: "years", created: testNow.Add(-(2*365 + 45) * 24 * time.Hour), want: "2y45d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithAgeColumn(), WithClock(testingclock.NewFakeClock(testNow)))
+
+			pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", CreationTimestamp: metav1.NewTime(tt.created)}}
+			table, err := c.ConvertToTable(context.TODO(), pod, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := table.ColumnDefinitions[1].Name; got != "Age" {
+				t.Errorf("expected Age column, got %q", got)
+			}
+			if got := table.Rows[0].Cells[1]; got != tt.want {
+				t.Errorf("expected age %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDefaultTableConvertorWithClock(t *testing.T) {
+	fakeClock := testingclock.NewFakeClock(testNow)
+	c := NewDefaultTableConvertorWithClock(schema.GroupResource{Resource: "pods"}, fakeClock)
+	if got := c.(*defaultTableConvertor).clock; got != fakeClock {
+		t.Fatalf("expected the provided clock to be used, got %#v", got)
+	}
+
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", CreationTimestamp: metav1.NewTime(testNow.Add(-time.Hour))}}
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithClock(fakeClock), WithAgeColumn()).ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClock.Step(2 * time.Hour)
+	later, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithClock(fakeClock), WithAgeColumn()).ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows[0].Cells[1] != "60m" || later.Rows[0].Cells[1] != "3h" {
+		t.Errorf("unexpected ages: %v then %v", table.Rows[0].Cells[1], later.Rows[0].Cells[1])
+	}
+}
+
+func TestDefaultTableConvertorIncludeObject(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	list := &example.PodList{Items: []example.Pod{*pod, *pod}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+
+	table, err := c.ConvertToTable(context.TODO(), list, &metav1.TableOptions{IncludeObject: metav1.IncludeNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(table.Rows))
+	}
+	for i, row := range table.Rows {
+		if row.Object.Object != nil || row.Object.Raw != nil {
+			t.Errorf("row %d: expected no embedded object, got %#v", i, row.Object)
+		}
+	}
+
+	table, err = c.ConvertToTable(context.TODO(), pod, &metav1.TableOptions{IncludeObject: metav1.IncludeObject})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows[0].Object.Object != pod {
+		t.Errorf("expected the object to be embedded, got %#v", table.Rows[0].Object)
+	}
+}
+
+func TestDefaultTableConvertorIncludeMetadata(t *testing.T) {
+	pod := &example.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar", Labels: map[string]string{"a": "b"}},
+		Spec:       example.PodSpec{NodeName: "node"},
+	}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+
+	for _, options := range []runtime.Object{nil, &metav1.TableOptions{}, &metav1.TableOptions{IncludeObject: metav1.IncludeMetadata}} {
+		table, err := c.ConvertToTable(context.TODO(), pod, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		partial, ok := table.Rows[0].Object.Object.(*metav1.PartialObjectMetadata)
+		if !ok {
+			t.Fatalf("%#v: expected PartialObjectMetadata, got %T", options, table.Rows[0].Object.Object)
+		}
+		if partial.APIVersion != "meta.k8s.io/v1" || partial.Kind != "PartialObjectMetadata" {
+			t.Errorf("%#v: unexpected type meta: %#v", options, partial.TypeMeta)
+		}
+		if !reflect.DeepEqual(partial.ObjectMeta, pod.ObjectMeta) {
+			t.Errorf("%#v: unexpected object meta: %#v", options, partial.ObjectMeta)
+		}
+	}
+
+	if _, err := c.ConvertToTable(context.TODO(), pod, &metav1.TableOptions{IncludeObject: "Unknown"}); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request for unknown policy, got %v", err)
+	}
+}
+
+type plainTableConvertor struct {
+	TableConvertor
+}
+
+func TestConvertToTableChunk(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	convertors := map[string]TableConvertor{
+		"chunk convertor": NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}),
+		"plain convertor": plainTableConvertor{NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})},
+	}
+	for name, c := range convertors {
+		t.Run(name, func(t *testing.T) {
+			first, err := ConvertToTableChunk(context.TODO(), c, pod, nil, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(first.ColumnDefinitions) != 2 {
+				t.Errorf("expected column definitions on the first chunk, got %#v", first.ColumnDefinitions)
+			}
+			next, err := ConvertToTableChunk(context.TODO(), c, pod, nil, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next.ColumnDefinitions != nil {
+				t.Errorf("expected no column definitions on later chunks, got %#v", next.ColumnDefinitions)
+			}
+			if len(next.Rows) != 1 || len(next.Rows[0].Cells) != 2 {
+				t.Errorf("expected one row with all cells, got %#v", next.Rows)
+			}
+			noHeaders, err := ConvertToTableChunk(context.TODO(), c, pod, &metav1.TableOptions{NoHeaders: true}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if noHeaders.ColumnDefinitions != nil {
+				t.Errorf("expected NoHeaders to suppress the first chunk headers, got %#v", noHeaders.ColumnDefinitions)
+			}
+		})
+	}
+}
+
+func TestDefaultTableConvertorInvalidTableOptions(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+
+	_, err := c.ConvertToTable(context.TODO(), pod, &metav1.ListOptions{})
+	if !errors.Is(err, ErrInvalidTableOptions) {
+		t.Fatalf("expected ErrInvalidTableOptions, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "*v1.ListOptions") {
+		t.Errorf("expected the error to name the options type, got %v", err)
+	}
+
+	var nilOptions *metav1.TableOptions
+	for _, options := range []runtime.Object{nil, nilOptions} {
+		if _, err := c.ConvertToTable(context.TODO(), pod, options); err != nil {
+			t.Errorf("%#v: unexpected error: %v", options, err)
+		}
+	}
+}
+
+func TestDefaultTableConvertorNamespaceColumn(t *testing.T) {
+	list := &example.PodList{Items: []example.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns2"}},
+	}}
+	allNamespaces := genericapirequest.WithRequestInfo(context.TODO(), &genericapirequest.RequestInfo{Resource: "pods"})
+	oneNamespace := genericapirequest.WithRequestInfo(context.TODO(), &genericapirequest.RequestInfo{Resource: "pods", Namespace: "ns1"})
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		opts    []TableConvertorOption
+		columns []string
+		cells   [][]interface{}
+	}{
+		{
+			name:    "disabled",
+			ctx:     allNamespaces,
+			columns: []string{"Name", "Created At"},
+			cells:   [][]interface{}{{"a"}, {"b"}},
+		},
+		{
+			name:    "all namespaces",
+			ctx:     allNamespaces,
+			opts:    []TableConvertorOption{WithNamespaceColumn()},
+			columns: []string{"Namespace", "Name", "Created At"},
+			cells:   [][]interface{}{{"ns1", "a"}, {"ns2", "b"}},
+		},
+		{
+			name:    "no request info",
+			ctx:     context.TODO(),
+			opts:    []TableConvertorOption{WithNamespaceColumn()},
+			columns: []string{"Namespace", "Name", "Created At"},
+			cells:   [][]interface{}{{"ns1", "a"}, {"ns2", "b"}},
+		},
+		{
+			name:    "single namespace",
+			ctx:     oneNamespace,
+			opts:    []TableConvertorOption{WithNamespaceColumn()},
+			columns: []string{"Name", "Created At"},
+			cells:   [][]interface{}{{"a"}, {"b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, tt.opts...).ConvertToTable(tt.ctx, list, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var columns []string
+			for _, def := range table.ColumnDefinitions {
+				columns = append(columns, def.Name)
+			}
+			if !reflect.DeepEqual(tt.columns, columns) {
+				t.Errorf("expected columns %v, got %v", tt.columns, columns)
+			}
+			for i, row := range table.Rows {
+				if len(row.Cells) != len(columns) {
+					t.Fatalf("row %d has %d cells for %d columns", i, len(row.Cells), len(columns))
+				}
+				if got := row.Cells[:len(tt.cells[i])]; !reflect.DeepEqual(tt.cells[i], got) {
+					t.Errorf("row %d: expected cells %v, got %v", i, tt.cells[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultTableConvertorLabelColumns(t *testing.T) {
+	list := &example.PodList{Items: []example.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"app": "web", "tier": "frontend"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Labels: map[string]string{"app": "db"}}},
+	}}
+	options := &ExtendedTableOptions{LabelColumns: []string{"tier", "app"}}
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), list, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 4 {
+		t.Fatalf("unexpected columns: %#v", table.ColumnDefinitions)
+	}
+	for i, name := range []string{"tier", "app"} {
+		def := table.ColumnDefinitions[2+i]
+		if def.Name != name || def.Type != "string" || def.Priority != 1 {
+			t.Errorf("unexpected label column definition: %#v", def)
+		}
+	}
+	expected := [][]interface{}{{"frontend", "web"}, {"", "db"}}
+	for i, row := range table.Rows {
+		if got := row.Cells[2:]; !reflect.DeepEqual(expected[i], got) {
+			t.Errorf("row %d: expected label cells %v, got %v", i, expected[i], got)
+		}
+	}
+	if copied := options.DeepCopyObject().(*ExtendedTableOptions); !reflect.DeepEqual(copied, options) {
+		t.Errorf("unexpected deep copy: %#v", copied)
+	}
+}
+
+func TestDefaultTableConvertorShowLabels(t *testing.T) {
+	list := &example.PodList{Items: []example.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"tier": "frontend", "app": "web", "env": "prod"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+	}}
+	options := &ExtendedTableOptions{LabelColumns: []string{"app"}, ShowLabels: true}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+	for i := 0; i < 10; i++ {
+		table, err := c.ConvertToTable(context.TODO(), list, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if def := table.ColumnDefinitions[3]; def.Name != "Labels" || def.Type != "string" {
+			t.Fatalf("unexpected labels column definition: %#v", def)
+		}
+		expected := [][]interface{}{{"web", "app=web,env=prod,tier=frontend"}, {"", ""}}
+		for i, row := range table.Rows {
+			if got := row.Cells[2:]; !reflect.DeepEqual(expected[i], got) {
+				t.Errorf("row %d: expected label cells %v, got %v", i, expected[i], got)
+			}
+		}
+	}
+	if copied := options.DeepCopyObject().(*ExtendedTableOptions); !reflect.DeepEqual(copied, options) {
+		t.Errorf("unexpected deep copy: %#v", copied)
+	}
+}
+
+func TestDefaultTableConvertorColumnDefinitionsCopied(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+	table, err := c.ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table.ColumnDefinitions[0].Name = "Modified"
+	table, err = c.ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.ColumnDefinitions[0].Name != "Name" {
+		t.Errorf("expected callers not to be able to modify the convertor columns, got %q", table.ColumnDefinitions[0].Name)
+	}
+}
+
+func BenchmarkDefaultTableConvertor(b *testing.B) {
+	list := &example.PodList{}
+	for i := 0; i < 100; i++ {
+		list.Items = append(list.Items, example.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), CreationTimestamp: metav1.NewTime(testNow)}})
+	}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+	ctx := context.TODO()
+	options := &metav1.TableOptions{IncludeObject: metav1.IncludeNone}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.ConvertToTable(ctx, list, options); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDefaultTableConvertorEmptyList(t *testing.T) {
+	remaining := int64(3)
+	list := &example.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "10", Continue: "token", RemainingItemCount: &remaining}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithAgeColumn())
+
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows != nil || len(table.ColumnDefinitions) != 2 || table.ColumnDefinitions[1].Name != "Age" {
+		t.Errorf("expected the headers without rows, got %#v", table)
+	}
+	if table.ResourceVersion != "10" || table.Continue != "token" || table.RemainingItemCount == nil || *table.RemainingItemCount != 3 {
+		t.Errorf("expected the list metadata, got %#v", table.ListMeta)
+	}
+	table.ColumnDefinitions[0].Name = "changed"
+	table.Rows = append(table.Rows, metav1.TableRow{})
+
+	table, err = c.ConvertToTable(context.TODO(), list, &metav1.TableOptions{NoHeaders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows != nil || table.ColumnDefinitions != nil {
+		t.Errorf("expected neither headers nor rows, got %#v", table)
+	}
+	if table, err = c.ConvertToTable(context.TODO(), list, nil); err != nil || table.ColumnDefinitions[0].Name != "Name" {
+		t.Errorf("expected callers not to share the tables of empty lists, got %#v, %v", table, err)
+	}
+}
+
+func BenchmarkDefaultTableConvertorEmptyList(b *testing.B) {
+	list := &example.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "10"}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+	ctx := context.TODO()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.ConvertToTable(ctx, list, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDefaultTableConvertorPassthrough(b *testing.B) {
+	list := &example.PodList{}
+	for i := 0; i < 100; i++ {
+		list.Items = append(list.Items, example.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), CreationTimestamp: metav1.NewTime(testNow)}})
+	}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+	ctx := context.TODO()
+	options := &metav1.TableOptions{IncludeObject: metav1.IncludeNone}
+	table, err := c.ConvertToTable(ctx, list, options)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("convert", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := c.ConvertToTable(ctx, list, options); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("passthrough", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := c.ConvertToTable(ctx, table, options); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestConvertToTableStream(t *testing.T) {
+	list := &example.PodList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "5", Continue: "token"},
+		Items: []example.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "c"}},
+		},
+	}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).(TableStreamConvertor)
+
+	var names []interface{}
+	table, err := c.ConvertToTableStream(context.TODO(), list, nil, func(row metav1.TableRow) error {
+		names = append(names, row.Cells[0])
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []interface{}{"a", "b", "c"}) {
+		t.Errorf("unexpected rows emitted: %v", names)
+	}
+	if table.Rows != nil || len(table.ColumnDefinitions) != 2 || table.ResourceVersion != "5" || table.Continue != "token" {
+		t.Errorf("unexpected table returned with the stream: %#v", table)
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	_, err = c.ConvertToTableStream(context.TODO(), list, nil, func(row metav1.TableRow) error {
+		count++
+		if count == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || count != 2 {
+		t.Errorf("expected emit error to stop the stream after 2 rows, got %v after %d rows", err, count)
+	}
+}
+
+func TestDefaultTableConvertorRowConditions(t *testing.T) {
+	list := &example.PodList{Items: []example.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "running"}, Status: example.PodStatus{Phase: "Running"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "done"}, Status: example.PodStatus{Phase: "Succeeded"}},
+	}}
+	completed := func(obj runtime.Object) []metav1.TableRowCondition {
+		if obj.(*example.Pod).Status.Phase != "Succeeded" {
+			return nil
+		}
+		return []metav1.TableRowCondition{{Type: metav1.RowCompleted, Status: metav1.ConditionTrue, Reason: "Succeeded"}}
+	}
+
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range table.Rows {
+		if row.Conditions != nil {
+			t.Errorf("row %d: expected no conditions by default, got %#v", i, row.Conditions)
+		}
+	}
+
+	table, err = NewDefaultTableConvertorWithRowConditions(schema.GroupResource{Resource: "pods"}, completed).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows[0].Conditions != nil {
+		t.Errorf("expected no conditions on the running pod, got %#v", table.Rows[0].Conditions)
+	}
+	if conditions := table.Rows[1].Conditions; len(conditions) != 1 || conditions[0].Type != metav1.RowCompleted {
+		t.Errorf("expected a Completed condition on the finished pod, got %#v", conditions)
+	}
+}
+
+func TestDefaultColumns(t *testing.T) {
+	expected := []metav1.TableColumnDefinition{
+		{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+		{Name: "Created At", Type: "date", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"]},
+	}
+	if columns := DefaultColumns(); !reflect.DeepEqual(expected, columns) {
+		t.Fatalf("unexpected default columns: %#v", columns)
+	}
+
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), &example.Pod{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, table.ColumnDefinitions) {
+		t.Errorf("expected the convertor to render the default columns, got %#v", table.ColumnDefinitions)
+	}
+}
+
+func TestDefaultTableConvertorNameFunc(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Spec: example.PodSpec{Hostname: "alias"}}
+	nameFunc := func(obj runtime.Object) string {
+		pod := obj.(*example.Pod)
+		return fmt.Sprintf("%s (%s)", pod.Name, pod.Spec.Hostname)
+	}
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithNameFunc(nameFunc)).ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := table.Rows[0].Cells[0]; got != "foo (alias)" {
+		t.Errorf("unexpected name cell %q", got)
+	}
+	if def := table.ColumnDefinitions[0]; def.Name != "Name" || def.Format != "name" {
+		t.Errorf("expected the name column to keep the name format, got %#v", def)
+	}
+}
+
+func TestIsNotAcceptable(t *testing.T) {
+	ctx := genericapirequest.WithRequestInfo(context.TODO(), &genericapirequest.RequestInfo{APIGroup: "example.com", Resource: "widgets"})
+	_, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(ctx, &metav1.Status{}, nil)
+	resource, ok := IsNotAcceptable(err)
+	if !ok {
+		t.Fatalf("expected a not acceptable error, got %v", err)
+	}
+	if expected := (schema.GroupResource{Group: "example.com", Resource: "widgets"}); resource != expected {
+		t.Errorf("expected resource %v, got %v", expected, resource)
+	}
+	if resource, ok := IsNotAcceptable(fmt.Errorf("wrapped: %w", err)); !ok || resource.Resource != "widgets" {
+		t.Errorf("expected wrapped errors to be recognized, got %v %v", resource, ok)
+	}
+	if _, ok := IsNotAcceptable(errors.New("other")); ok {
+		t.Errorf("expected other errors not to be recognized")
+	}
+}
+
+func TestWithTableResource(t *testing.T) {
+	widgets := schema.GroupResource{Group: "example.com", Resource: "widgets"}
+	c, err := NewJSONPathTableConvertor([]PrinterColumn{{Name: "Name", Type: "string", JSONPath: ".metadata.name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requested := genericapirequest.WithRequestInfo(context.TODO(), &genericapirequest.RequestInfo{Resource: "pods"})
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		expected schema.GroupResource
+	}{
+		{name: "empty context", ctx: context.TODO(), expected: schema.GroupResource{}},
+		{name: "request info", ctx: requested, expected: schema.GroupResource{Resource: "pods"}},
+		{name: "explicit resource", ctx: WithTableResource(context.TODO(), widgets), expected: widgets},
+		{name: "explicit resource within a request", ctx: WithTableResource(requested, widgets), expected: widgets},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := c.ConvertToTable(tc.ctx, &metav1.Status{}, nil)
+			if resource, ok := IsNotAcceptable(err); !ok || resource != tc.expected {
+				t.Errorf("expected a not acceptable error for %v, got %v", tc.expected, err)
+			}
+		})
+	}
+	_, err = c.ConvertToTable(WithTableResource(context.TODO(), widgets), &metav1.Status{}, nil)
+	if expected := "the resource widgets.example.com does not support being converted to a Table"; err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestDefaultTableConvertorSkipInaccessible(t *testing.T) {
+	list := &metav1.List{Items: []runtime.RawExtension{
+		{Object: newUnstructured("foo", nil)},
+		{Object: &metav1.Status{Message: "not an object"}},
+		{Object: newUnstructured("bar", nil)},
+	}}
+	_, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "widgets"}).ConvertToTable(context.TODO(), list, nil)
+	if _, ok := IsNotAcceptable(err); !ok {
+		t.Errorf("expected a not acceptable error without the option, got %v", err)
+	}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "widgets"}, WithSkipInaccessible())
+	recorder := &fakeWarningRecorder{}
+	table, err := c.ConvertToTable(warning.WithWarningRecorder(context.TODO(), recorder), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []interface{}
+	for _, row := range table.Rows {
+		names = append(names, row.Cells[0])
+	}
+	if expected := []interface{}{"foo", "bar"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected rows %v, got %v", expected, names)
+	}
+	if expected := []string{"items of widgets without object metadata omitted from the table: 1"}; !reflect.DeepEqual(expected, recorder.warnings) {
+		t.Errorf("expected warnings %v, got %v", expected, recorder.warnings)
+	}
+
+	_, err = c.ConvertToTable(context.TODO(), &metav1.Status{}, nil)
+	if _, ok := IsNotAcceptable(err); !ok {
+		t.Errorf("expected a not acceptable error for a single object, got %v", err)
+	}
+}
+
+func TestDefaultTableConvertorCancellation(t *testing.T) {
+	list := &example.PodList{Items: make([]example.Pod, 10000)}
+	for i := range list.Items {
+		list.Items[i].Name = fmt.Sprintf("pod-%d", i)
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	emitted := 0
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).(TableStreamConvertor)
+	_, err := c.ConvertToTableStream(ctx, list, nil, func(metav1.TableRow) error {
+		emitted++
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if emitted != 0 {
+		t.Errorf("expected conversion to stop immediately, got %d rows", emitted)
+	}
+
+	ctx, cancel = context.WithCancel(context.TODO())
+	defer cancel()
+	emitted = 0
+	_, err = c.ConvertToTableStream(ctx, list, nil, func(metav1.TableRow) error {
+		emitted++
+		if emitted == 150 {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if emitted > 150+cancellationCheckInterval {
+		t.Errorf("expected conversion to stop promptly after cancellation, got %d rows", emitted)
+	}
+}
+
+func TestDefaultTableConvertorRemainingItemCount(t *testing.T) {
+	remaining := int64(5)
+	tests := []struct {
+		name     string
+		list     metav1.ListMeta
+		expected *int64
+	}{
+		{name: "paginated", list: metav1.ListMeta{Continue: "token", RemainingItemCount: &remaining}, expected: &remaining},
+		{name: "not paginated", list: metav1.ListMeta{RemainingItemCount: &remaining}},
+		{name: "last page", list: metav1.ListMeta{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := &example.PodList{ListMeta: tt.list}
+			table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), list, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.expected, table.RemainingItemCount) {
+				t.Errorf("expected remaining item count %v, got %v", tt.expected, table.RemainingItemCount)
+			}
+		})
+	}
+}
+
+func TestDefaultTableConvertorColumnDescriptions(t *testing.T) {
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithColumnDescriptions(map[string]string{
+		"Name":    "The name of the pod.",
+		"Unknown": "Ignored.",
+	}))
+	table, err := c.ConvertToTable(context.TODO(), &example.Pod{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := table.ColumnDefinitions[0].Description; got != "The name of the pod." {
+		t.Errorf("expected the name description to be overridden, got %q", got)
+	}
+	if got := table.ColumnDefinitions[1].Description; got != DefaultColumns()[1].Description {
+		t.Errorf("expected other descriptions to be unchanged, got %q", got)
+	}
+	if len(table.ColumnDefinitions) != 2 {
+		t.Errorf("expected unknown names to be ignored, got %#v", table.ColumnDefinitions)
+	}
+}
+
+func TestDefaultTableConvertorStatus(t *testing.T) {
+	status := &metav1.Status{Status: metav1.StatusSuccess, Reason: "Deleted", Message: "pod foo deleted"}
+
+	if _, ok := IsNotAcceptable(func() error {
+		_, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), status, nil)
+		return err
+	}()); !ok {
+		t.Errorf("expected a Status not to be acceptable by default")
+	}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithStatusRows())
+	table, err := c.ConvertToTable(context.TODO(), status, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var columns []string
+	for _, def := range table.ColumnDefinitions {
+		columns = append(columns, def.Name)
+	}
+	if expected := []string{"Status", "Reason", "Message"}; !reflect.DeepEqual(expected, columns) {
+		t.Errorf("expected columns %v, got %v", expected, columns)
+	}
+	if len(table.Rows) != 1 {
+		t.Fatalf("expected a single row, got %d", len(table.Rows))
+	}
+	if expected := []interface{}{"Success", "Deleted", "pod foo deleted"}; !reflect.DeepEqual(expected, table.Rows[0].Cells) {
+		t.Errorf("expected cells %v, got %v", expected, table.Rows[0].Cells)
+	}
+	if table.Rows[0].Object.Object != status {
+		t.Errorf("expected the status to be embedded, got %#v", table.Rows[0].Object)
+	}
+
+	table, err = c.ConvertToTable(context.TODO(), status, &metav1.TableOptions{NoHeaders: true, IncludeObject: metav1.IncludeNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.ColumnDefinitions != nil || table.Rows[0].Object.Object != nil {
+		t.Errorf("expected options to be honored, got %#v", table)
+	}
+}
+
+func TestDefaultTableConvertorPassthrough(t *testing.T) {
+	existing := &metav1.Table{
+		ListMeta:          metav1.ListMeta{ResourceVersion: "7"},
+		ColumnDefinitions: DefaultColumns(),
+		Rows:              []metav1.TableRow{{Cells: []interface{}{"foo", "2021-06-01T12:00:00Z"}}},
+	}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithTableValidation())
+
+	table, err := c.ConvertToTable(context.TODO(), existing, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(existing, table) {
+		t.Errorf("expected the table to be returned as is, got %#v", table)
+	}
+	table.Rows[0].Object.Raw = []byte("{}")
+	if existing.Rows[0].Object.Raw != nil {
+		t.Errorf("modifying the returned rows must not modify the existing table")
+	}
+
+	table, err = c.ConvertToTable(context.TODO(), existing, &metav1.TableOptions{NoHeaders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.ColumnDefinitions != nil || !reflect.DeepEqual(existing.Rows, table.Rows) || table.ResourceVersion != "7" {
+		t.Errorf("unexpected table without headers: %#v", table)
+	}
+	if existing.ColumnDefinitions == nil {
+		t.Errorf("the existing table must not be modified")
+	}
+
+	var rows []metav1.TableRow
+	table, err = c.(TableStreamConvertor).ConvertToTableStream(context.TODO(), existing, nil, func(row metav1.TableRow) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(existing.Rows, rows) || table.Rows != nil {
+		t.Errorf("expected the rows to be streamed, got %#v and %#v", rows, table)
+	}
+
+	invalid := &metav1.Table{ColumnDefinitions: DefaultColumns(), Rows: []metav1.TableRow{{Cells: []interface{}{"foo"}}}}
+	if _, err := c.ConvertToTable(context.TODO(), invalid, nil); err == nil {
+		t.Errorf("expected an invalid table to be rejected")
+	}
+}
+
+func TestSetMetadataDescriptions(t *testing.T) {
+	original := metadataDescriptions()
+	if len(original["name"]) == 0 {
+		t.Fatalf("expected the name description to default to the swagger documentation")
+	}
+	defer SetMetadataDescriptions(original)
+
+	SetMetadataDescriptions(map[string]string{"name": "The name.", "creationTimestamp": "The creation time."})
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), &example.Pod{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var descriptions []string
+	for _, def := range table.ColumnDefinitions {
+		descriptions = append(descriptions, def.Description)
+	}
+	if expected := []string{"The name.", "The creation time."}; !reflect.DeepEqual(expected, descriptions) {
+		t.Errorf("expected descriptions %v, got %v", expected, descriptions)
+	}
+}
+
+func TestDefaultTableConvertorStrictCells(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	testCases := []struct {
+		name  string
+		cell  interface{}
+		valid bool
+	}{
+		{name: "string", cell: "a", valid: true},
+		{name: "integer", cell: int32(1), valid: true},
+		{name: "number", cell: 1.5, valid: true},
+		{name: "boolean", cell: true, valid: true},
+		{name: "nil", cell: nil, valid: true},
+		{name: "slice", cell: []interface{}{"a", int64(1)}, valid: true},
+		{name: "map", cell: map[string]string{"a": "b"}, valid: true},
+		{name: "nested", cell: map[string]interface{}{"a": []string{"b"}}, valid: true},
+		{name: "struct", cell: metav1.ObjectMeta{}},
+		{name: "pointer", cell: &metav1.Time{}},
+		{name: "channel", cell: make(chan int)},
+		{name: "function", cell: func() {}},
+		{name: "map with integer keys", cell: map[int]string{1: "a"}},
+		{name: "slice of structs", cell: []interface{}{struct{}{}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cell := tc.cell
+			c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithStrictCells(), WithExtraColumns(ExtraColumn{
+				Definition: metav1.TableColumnDefinition{Name: "Value", Type: "string"},
+				Extract:    func(runtime.Object) (interface{}, error) { return cell, nil },
+			}))
+			_, err := c.ConvertToTable(context.TODO(), pod, nil)
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && (err == nil || !strings.Contains(err.Error(), `column "Value" of foo`)) {
+				t.Errorf("expected a descriptive error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDefaultTableConvertorAgeFormat(t *testing.T) {
+	testCases := []struct {
+		name  string
+		age   time.Duration
+		short string
+		long  string
+		exact string
+	}{
+		{name: "sub-minute", age: 45*time.Second + 300*time.Millisecond, short: "45s", long: "45s", exact: "45s"},
+		{name: "multi-day", age: 76*time.Hour + 3*time.Minute + 4*time.Second, short: "3d", long: "3d4h", exact: "76h3m4s"},
+		{name: "multi-year", age: 2*365*24*time.Hour + 15*24*time.Hour, short: "2y", long: "2y15d", exact: "17880h0m0s"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", CreationTimestamp: metav1.NewTime(testNow.Add(-tc.age))}}
+			for format, expected := range map[string]struct {
+				format AgeFormat
+				cell   string
+			}{
+				"short": {AgeShort, tc.short},
+				"long":  {AgeLong, tc.long},
+				"exact": {AgeExact, tc.exact},
+			} {
+				c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithClock(testingclock.NewFakeClock(testNow)), WithAgeFormat(expected.format))
+				table, err := c.ConvertToTable(context.TODO(), pod, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if table.ColumnDefinitions[1].Name != "Age" {
+					t.Errorf("%s: expected an Age column, got %#v", format, table.ColumnDefinitions[1])
+				}
+				if cell := table.Rows[0].Cells[1]; cell != expected.cell {
+					t.Errorf("%s: expected %q, got %q", format, expected.cell, cell)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultTableConvertorTimestampPrecision(t *testing.T) {
+	list := &examplev1.PodList{Items: []examplev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "first", CreationTimestamp: metav1.NewTime(testNow.Add(120 * time.Millisecond))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "second", CreationTimestamp: metav1.NewTime(testNow.Add(450 * time.Millisecond))}},
+	}}
+	tests := []struct {
+		name     string
+		opts     []TableConvertorOption
+		expected []interface{}
+	}{
+		{name: "default", expected: []interface{}{"2021-06-01T12:00:00Z", "2021-06-01T12:00:00Z"}},
+		{name: "nanoseconds", opts: []TableConvertorOption{WithTimestampPrecision(time.RFC3339Nano)}, expected: []interface{}{"2021-06-01T12:00:00.12Z", "2021-06-01T12:00:00.45Z"}},
+		{name: "milliseconds", opts: []TableConvertorOption{WithTimestampPrecision("2006-01-02T15:04:05.000Z07:00")}, expected: []interface{}{"2021-06-01T12:00:00.120Z", "2021-06-01T12:00:00.450Z"}},
+		{name: "age", opts: []TableConvertorOption{WithTimestampPrecision(time.RFC3339Nano), WithAgeColumn(), WithClock(testingclock.NewFakeClock(testNow.Add(time.Hour)))}, expected: []interface{}{"59m", "59m"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, tc.opts...)
+			table, err := c.ConvertToTable(context.TODO(), list, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var cells []interface{}
+			for _, row := range table.Rows {
+				cells = append(cells, row.Cells[1])
+			}
+			if !reflect.DeepEqual(tc.expected, cells) {
+				t.Errorf("expected cells %q, got %q", tc.expected, cells)
+			}
+		})
+	}
+
+	pod := newUnstructured("foo", map[string]interface{}{"status": map[string]interface{}{"startTime": "2021-06-01T12:00:00.25Z"}})
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithTimestampColumn("Started", ".status.startTime"), WithTimestampPrecision(time.RFC3339Nano))
+	table, err := c.ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cell := table.Rows[0].Cells[2]; cell != "2021-06-01T12:00:00.25Z" {
+		t.Errorf("expected the layout to apply to timestamp columns, got %q", cell)
+	}
+}
+
+// itemsOnlyList is a list type without list metadata.
+type itemsOnlyList struct {
+	Items []example.Pod
+}
+
+func (l *itemsOnlyList) GetObjectKind() schema.ObjectKind { return schema.EmptyObjectKind }
+func (l *itemsOnlyList) DeepCopyObject() runtime.Object {
+	return &itemsOnlyList{Items: append([]example.Pod(nil), l.Items...)}
+}
+
+func TestDefaultTableConvertorListMetadata(t *testing.T) {
+	list := &itemsOnlyList{Items: []example.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}}}
+
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatalf("expected list metadata to be best-effort, got %v", err)
+	}
+	if len(table.Rows) != 1 || len(table.ResourceVersion) > 0 {
+		t.Errorf("unexpected table: %#v", table)
+	}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithStrictListMetadata())
+	if _, err := c.ConvertToTable(context.TODO(), list, nil); err == nil || !strings.Contains(err.Error(), "itemsOnlyList") {
+		t.Errorf("expected an error naming the list type, got %v", err)
+	}
+	if _, err := c.ConvertToTable(context.TODO(), &example.PodList{Items: list.Items}, nil); err != nil {
+		t.Errorf("unexpected error for a list with metadata: %v", err)
+	}
+}
+
+// aggregatedList is a list type whose only metadata is an object metadata, without a continue
+// token.
+type aggregatedList struct {
+	metav1.ObjectMeta
+	Continue string
+	Items    []example.Pod
+}
+
+func (l *aggregatedList) GetObjectKind() schema.ObjectKind { return schema.EmptyObjectKind }
+func (l *aggregatedList) DeepCopyObject() runtime.Object {
+	out := *l
+	out.Items = append([]example.Pod(nil), l.Items...)
+	return &out
+}
+
+func TestListMetaTableConvertor(t *testing.T) {
+	list := &aggregatedList{
+		ObjectMeta: metav1.ObjectMeta{ResourceVersion: "10"},
+		Continue:   "token",
+		Items:      []example.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "foo", ResourceVersion: "7"}}},
+	}
+
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.ResourceVersion != "10" || len(table.Continue) > 0 {
+		t.Errorf("expected only the common metadata without an extractor, got %#v", table.ListMeta)
+	}
+
+	remaining := int64(4)
+	c := NewListMetaTableConvertor(schema.GroupResource{Resource: "pods"}, func(obj runtime.Object) (metav1.ListInterface, error) {
+		l, ok := obj.(*aggregatedList)
+		if !ok {
+			return nil, fmt.Errorf("unexpected list %T", obj)
+		}
+		return &metav1.ListMeta{ResourceVersion: l.ResourceVersion, Continue: l.Continue, RemainingItemCount: &remaining}, nil
+	}, WithAgeColumn())
+	table, err = c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.ResourceVersion != "10" || table.Continue != "token" || table.RemainingItemCount == nil || *table.RemainingItemCount != 4 {
+		t.Errorf("expected the extracted list metadata, got %#v", table.ListMeta)
+	}
+	if len(table.Rows) != 1 || table.ColumnDefinitions[1].Name != "Age" {
+		t.Errorf("expected the options to apply, got %#v", table)
+	}
+
+	table, err = c.ConvertToTable(context.TODO(), &list.Items[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.ResourceVersion != "7" {
+		t.Errorf("expected the metadata of a single object to be read from the object, got %#v", table.ListMeta)
+	}
+
+	if _, err := c.ConvertToTable(context.TODO(), &example.PodList{}, nil); err == nil || !strings.Contains(err.Error(), "unexpected list") {
+		t.Errorf("expected the extractor error, got %v", err)
+	}
+}
+
+func TestDefaultTableConvertorHybridList(t *testing.T) {
+	list := &aggregatedList{
+		ObjectMeta: metav1.ObjectMeta{Name: "aggregate", ResourceVersion: "10", SelfLink: "/aggregates/aggregate"},
+		Items: []example.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+		},
+	}
+	if _, err := meta.Accessor(list); err != nil || !meta.IsListType(list) {
+		t.Fatalf("expected a list with object metadata, got %v", err)
+	}
+
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithStrictListMetadata()).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []interface{}
+	for _, row := range table.Rows {
+		names = append(names, row.Cells[0])
+	}
+	if expected := []interface{}{"a", "b"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected a row per item and none for the list, got %v", names)
+	}
+	if table.ResourceVersion != "10" || table.SelfLink != "/aggregates/aggregate" {
+		t.Errorf("expected the object metadata of the list to provide the list metadata, got %#v", table.ListMeta)
+	}
+
+	list.Items = nil
+	table, err = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 0 {
+		t.Errorf("expected an empty list to have no row, got %v", table.Rows)
+	}
+}
+
+func TestDefaultTableConvertorObjectConvertor(t *testing.T) {
+	scheme := runtime.NewScheme()
+	install.Install(scheme)
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Spec: example.PodSpec{NodeName: "node1"}}
+	options := &metav1.TableOptions{IncludeObject: metav1.IncludeObject}
+
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), pod, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows[0].Object.Object != pod {
+		t.Errorf("expected the in-memory object without a convertor, got %#v", table.Rows[0].Object.Object)
+	}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithObjectConvertor(scheme, examplev1.SchemeGroupVersion))
+	table, err = c.ConvertToTable(context.TODO(), pod, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned, ok := table.Rows[0].Object.Object.(*examplev1.Pod)
+	if !ok {
+		t.Fatalf("expected a versioned pod, got %T", table.Rows[0].Object.Object)
+	}
+	if versioned.Spec.NodeName != "node1" || versioned.APIVersion != examplev1.SchemeGroupVersion.String() {
+		t.Errorf("unexpected conversion: %#v", versioned)
+	}
+
+	table, err = c.ConvertToTable(context.TODO(), pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := table.Rows[0].Object.Object.(*metav1.PartialObjectMetadata); !ok {
+		t.Errorf("expected only the Object policy to convert objects, got %T", table.Rows[0].Object.Object)
+	}
+
+	unknown := schema.GroupVersion{Group: "unknown.example.com", Version: "v1"}
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithObjectConvertor(scheme, unknown))
+	if _, err := c.ConvertToTable(context.TODO(), pod, options); err == nil {
+		t.Errorf("expected a conversion to an unknown version to fail")
+	}
+}
+
+func TestDefaultTableConvertorScheme(t *testing.T) {
+	scheme := runtime.NewScheme()
+	install.Install(scheme)
+	options := &metav1.TableOptions{IncludeObject: metav1.IncludeObject}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithScheme(scheme))
+
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Spec: example.PodSpec{NodeName: "node1"}}
+	table, err := c.ConvertToTable(context.TODO(), pod, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned, ok := table.Rows[0].Object.Object.(*examplev1.Pod)
+	if !ok {
+		t.Fatalf("expected the internal pod to be embedded at an external version, got %T", table.Rows[0].Object.Object)
+	}
+	if versioned.Spec.NodeName != "node1" || versioned.APIVersion != examplev1.SchemeGroupVersion.String() || versioned.Kind != "Pod" {
+		t.Errorf("unexpected conversion: %#v", versioned)
+	}
+
+	external := &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bar"}}
+	if table, err = c.ConvertToTable(context.TODO(), external, options); err != nil {
+		t.Fatal(err)
+	}
+	embedded := table.Rows[0].Object.Object.(*examplev1.Pod)
+	if embedded == external || embedded.APIVersion != examplev1.SchemeGroupVersion.String() || embedded.Kind != "Pod" {
+		t.Errorf("expected a copy of the external pod with its kind set, got %#v", embedded)
+	}
+	if !external.GetObjectKind().GroupVersionKind().Empty() {
+		t.Errorf("expected the converted pod to be left unchanged, got %#v", external.TypeMeta)
+	}
+
+	unknown := newUnstructured("baz", nil)
+	if table, err = c.ConvertToTable(context.TODO(), unknown, options); err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows[0].Object.Object != unknown {
+		t.Errorf("expected objects unknown to the scheme to be embedded as is, got %#v", table.Rows[0].Object.Object)
+	}
+}
+
+func TestDefaultTableConvertorConcurrency(t *testing.T) {
+	list := &examplev1.PodList{}
+	for i := 0; i < 50; i++ {
+		list.Items = append(list.Items, examplev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("pod-%d", i%7),
+			Namespace:         "default",
+			Labels:            map[string]string{"app": fmt.Sprintf("app-%d", i%3)},
+			CreationTimestamp: metav1.NewTime(testNow.Add(-time.Duration(i) * time.Minute)),
+		}})
+	}
+	jsonPath, err := NewJSONPathTableConvertor([]PrinterColumn{{Name: "Name", Type: "string", JSONPath: ".metadata.name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	convertors := []TableConvertor{
+		NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"},
+			WithNamespaceColumn(),
+			WithAgeColumn(),
+			WithClock(testingclock.NewFakeClock(testNow)),
+			WithConditionStatusColumn("Ready"),
+			SortByColumn("Name", true),
+			WithTableValidation(),
+		),
+		jsonPath,
+	}
+	cached, err := convertors[0].ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	optionsList := []runtime.Object{
+		nil,
+		&metav1.TableOptions{NoHeaders: true, IncludeObject: metav1.IncludeObject},
+		&ExtendedTableOptions{LabelColumns: []string{"app"}},
+	}
+
+	ctx := genericapirequest.WithRequestInfo(context.TODO(), &genericapirequest.RequestInfo{Resource: "pods"})
+	done := make(chan error)
+	workers := 0
+	for _, c := range convertors {
+		for _, options := range optionsList {
+			for _, object := range []runtime.Object{list, &list.Items[0], cached} {
+				workers++
+				go func(c TableConvertor, object, options runtime.Object) {
+					for i := 0; i < 20; i++ {
+						table, err := c.ConvertToTable(ctx, object, options)
+						if err != nil {
+							done <- err
+							return
+						}
+						// callers own the returned table, the handler modifies embedded objects
+						for j := range table.Rows {
+							table.Rows[j].Object = runtime.RawExtension{}
+						}
+					}
+					done <- nil
+				}(c, object, options)
+			}
+		}
+	}
+	for i := 0; i < workers; i++ {
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDefaultTableConvertorNeverIncludeObject(t *testing.T) {
+	list := &example.PodList{Items: []example.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "secrets"}, WithNeverIncludeObject())
+	encoder := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{})
+	for _, ctx := range []context.Context{context.TODO(), WithEmbeddedObjectEncoder(context.TODO(), encoder)} {
+		for _, policy := range []metav1.IncludeObjectPolicy{"", metav1.IncludeMetadata, metav1.IncludeObject} {
+			table, err := c.ConvertToTable(ctx, list, &metav1.TableOptions{IncludeObject: policy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if object := table.Rows[0].Object; object.Object != nil || object.Raw != nil {
+				t.Errorf("%q: expected no embedded object, got %#v", policy, object)
+			}
+			if table.Rows[0].Cells[0] != "foo" {
+				t.Errorf("%q: unexpected cells %v", policy, table.Rows[0].Cells)
+			}
+		}
+	}
+}
+
+func TestDefaultTableConvertorNeverIncludeObjectPassthrough(t *testing.T) {
+	existing := &metav1.Table{
+		ColumnDefinitions: DefaultColumns(),
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"foo", ""}, Object: runtime.RawExtension{Object: &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Spec: examplev1.PodSpec{NodeName: "node"}}}},
+			{Cells: []interface{}{"bar", ""}, Object: runtime.RawExtension{Raw: []byte(`{"kind":"Pod","metadata":{"name":"bar"},"spec":{"nodeName":"node"}}`)}},
+		},
+	}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "secrets"}, WithNeverIncludeObject())
+	for _, policy := range []metav1.IncludeObjectPolicy{"", metav1.IncludeMetadata, metav1.IncludeObject} {
+		table, err := c.ConvertToTable(context.TODO(), existing, &metav1.TableOptions{IncludeObject: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, row := range table.Rows {
+			if row.Object.Object != nil || row.Object.Raw != nil {
+				t.Errorf("%q: row %d: expected no embedded object, got %#v", policy, i, row.Object)
+			}
+		}
+	}
+	if existing.Rows[0].Object.Object == nil || existing.Rows[1].Object.Raw == nil {
+		t.Errorf("the existing table must not be modified")
+	}
+
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), existing, &metav1.TableOptions{IncludeObject: metav1.IncludeMetadata})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"foo", "bar"} {
+		partial, ok := table.Rows[i].Object.Object.(*metav1.PartialObjectMetadata)
+		if !ok || partial.Name != name || partial.Kind != "PartialObjectMetadata" || partial.APIVersion != "meta.k8s.io/v1" {
+			t.Errorf("row %d: expected the metadata of %s, got %#v", i, name, table.Rows[i].Object)
+		}
+	}
+}
+
+func TestDefaultTableConvertorCompositeName(t *testing.T) {
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithNamespaceColumn(), WithCompositeName("Namespace", "Name", "Unknown"))
+	table, err := c.ConvertToTable(context.TODO(), &example.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formats := map[string]string{}
+	for _, def := range table.ColumnDefinitions {
+		formats[def.Name] = def.Format
+	}
+	if expected := map[string]string{"Namespace": "name", "Name": "name", "Created At": ""}; !reflect.DeepEqual(expected, formats) {
+		t.Errorf("expected formats %v, got %v", expected, formats)
+	}
+	if DefaultColumns()[0].Format != "name" || DefaultColumns()[1].Format != "" {
+		t.Errorf("the default columns must not be modified")
+	}
+}
+
+func TestDefaultTableConvertorZeroCreationTimestamp(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	for _, tc := range []struct {
+		name     string
+		opts     []TableConvertorOption
+		expected interface{}
+	}{
+		{name: "created at", expected: ""},
+		{name: "age", opts: []TableConvertorOption{WithAgeColumn()}, expected: "<unknown>"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, tc.opts...).ConvertToTable(context.TODO(), pod, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cell := table.Rows[0].Cells[1]; cell != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, cell)
+			}
+		})
+	}
+}
+
+func TestDefaultTableConvertorRowFilter(t *testing.T) {
+	remaining := int64(10)
+	list := &example.PodList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "5", Continue: "token", RemainingItemCount: &remaining},
+		Items: []example.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "system-a"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "system-b"}},
+		},
+	}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithRowFilter(func(obj runtime.Object) bool {
+		return !strings.HasPrefix(obj.(*example.Pod).Name, "system-")
+	}))
+
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []interface{}
+	for _, row := range table.Rows {
+		names = append(names, row.Cells[0])
+	}
+	if expected := []interface{}{"app", "other"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected rows %v, got %v", expected, names)
+	}
+	if table.ResourceVersion != "5" || table.Continue != "token" {
+		t.Errorf("expected the list metadata to be kept, got %#v", table.ListMeta)
+	}
+	if table.RemainingItemCount != nil {
+		t.Errorf("expected the remaining item count to be cleared, got %d", *table.RemainingItemCount)
+	}
+
+	table, err = c.ConvertToTable(context.TODO(), &list.Items[1], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 0 {
+		t.Errorf("expected a filtered object to have no row, got %v", table.Rows)
+	}
+}
+
+func TestDefaultTableConvertorBestEffort(t *testing.T) {
+	list := &example.PodList{Items: []example.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "good"}, Spec: example.PodSpec{NodeName: "node1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "bad"}},
+	}}
+	node := ExtraColumn{
+		Definition: metav1.TableColumnDefinition{Name: "Node", Type: "string"},
+		Extract: func(obj runtime.Object) (interface{}, error) {
+			if name := obj.(*example.Pod).Spec.NodeName; len(name) > 0 {
+				return name, nil
+			}
+			return nil, fmt.Errorf("not scheduled")
+		},
+	}
+	ready := RowConditionsFunc(func(obj runtime.Object) []metav1.TableRowCondition {
+		return []metav1.TableRowCondition{{Type: metav1.RowCompleted, Status: metav1.ConditionFalse}}
+	})
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithExtraColumns(node), WithFailOnExtraColumnError())
+	if _, err := c.ConvertToTable(context.TODO(), list, nil); err == nil {
+		t.Fatalf("expected the conversion to fail without best effort")
+	}
+
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithExtraColumns(node), WithFailOnExtraColumnError(), WithRowConditions(ready), WithBestEffort())
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 2 {
+		t.Fatalf("expected a row per object, got %d", len(table.Rows))
+	}
+	if cells := table.Rows[0].Cells; cells[0] != "good" || cells[2] != "node1" || len(table.Rows[0].Conditions) != 1 {
+		t.Errorf("unexpected good row: %#v", table.Rows[0])
+	}
+	bad := table.Rows[1]
+	if bad.Cells[0] != "bad" || bad.Cells[2] != nil {
+		t.Errorf("expected the failing cell to be empty, got %v", bad.Cells)
+	}
+	if len(bad.Conditions) != 2 || bad.Conditions[1].Type != RowConversionFailed || bad.Conditions[1].Message != "not scheduled" {
+		t.Errorf("unexpected conditions: %#v", bad.Conditions)
+	}
+	if err := RowErrors(table); err == nil || err.Error() != "row 1: not scheduled" {
+		t.Errorf("unexpected row errors: %v", err)
+	}
+	if err := RowErrors(&metav1.Table{Rows: table.Rows[:1]}); err != nil {
+		t.Errorf("expected no row errors, got %v", err)
+	}
+}
+
+func TestDefaultTableConvertorMaxRows(t *testing.T) {
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithMaxRows(2))
+	remaining := int64(10)
+	testCases := []struct {
+		name              string
+		list              *example.PodList
+		expectedRows      int
+		expectedRemaining *int64
+		expectedWarnings  []string
+	}{
+		{name: "under the limit", list: podListOf(2), expectedRows: 2},
+		{name: "complete list", list: podListOf(5), expectedRows: 2, expectedRemaining: int64Ptr(3), expectedWarnings: []string{"the table of pods was truncated to 2 rows"}},
+		{name: "counted page", list: func() *example.PodList {
+			list := podListOf(5)
+			list.Continue, list.RemainingItemCount = "token", &remaining
+			return list
+		}(), expectedRows: 2, expectedRemaining: int64Ptr(13), expectedWarnings: []string{"the table of pods was truncated to 2 rows"}},
+		{name: "uncounted page", list: func() *example.PodList {
+			list := podListOf(5)
+			list.Continue = "token"
+			return list
+		}(), expectedRows: 2, expectedWarnings: []string{"the table of pods was truncated to 2 rows"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := &fakeWarningRecorder{}
+			table, err := c.ConvertToTable(warning.WithWarningRecorder(context.TODO(), recorder), tc.list, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(table.Rows) != tc.expectedRows || table.Rows[0].Cells[0] != "pod-0" {
+				t.Errorf("expected the first %d rows, got %v", tc.expectedRows, table.Rows)
+			}
+			if !reflect.DeepEqual(tc.expectedRemaining, table.RemainingItemCount) {
+				t.Errorf("expected remaining item count %v, got %v", tc.expectedRemaining, table.RemainingItemCount)
+			}
+			if table.Continue != tc.list.Continue {
+				t.Errorf("expected continue token %q, got %q", tc.list.Continue, table.Continue)
+			}
+			if !reflect.DeepEqual(tc.expectedWarnings, recorder.warnings) {
+				t.Errorf("expected warnings %v, got %v", tc.expectedWarnings, recorder.warnings)
+			}
+		})
+	}
+
+	visited := 0
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithMaxRows(3), WithRowFilter(func(runtime.Object) bool {
+		visited++
+		return true
+	}))
+	var emitted int
+	table, err := c.(TableStreamConvertor).ConvertToTableStream(context.TODO(), podListOf(100), nil, func(metav1.TableRow) error {
+		emitted++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if emitted != 3 || visited != 3 {
+		t.Errorf("expected the stream to stop after 3 rows, emitted %d rows and visited %d objects", emitted, visited)
+	}
+	if table.RemainingItemCount != nil {
+		t.Errorf("expected no remaining item count with a row filter, got %d", *table.RemainingItemCount)
+	}
+}
+
+func TestDefaultTableConvertorIncludeColumns(t *testing.T) {
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns1", CreationTimestamp: metav1.NewTime(testNow.Add(-time.Hour))}, Spec: example.PodSpec{NodeName: "node1"}}
+	node := WithExtraColumns(ExtraColumn{Definition: metav1.TableColumnDefinition{Name: "Node", Type: "string"}, Extract: podNodeName})
+	clock := WithClock(testingclock.NewFakeClock(testNow))
+	testCases := []struct {
+		name            string
+		options         []TableConvertorOption
+		expectedColumns []string
+		expectedCells   []interface{}
+	}{
+		{name: "name only", options: []TableConvertorOption{WithIncludeColumns("Name")}, expectedColumns: []string{"Name"}, expectedCells: []interface{}{"foo"}},
+		{name: "convertor order", options: []TableConvertorOption{clock, WithAgeColumn(), WithNamespaceColumn(), node, WithIncludeColumns("Node", "Age", "Namespace")}, expectedColumns: []string{"Namespace", "Age", "Node"}, expectedCells: []interface{}{"ns1", "60m", "node1"}},
+		{name: "repeated options", options: []TableConvertorOption{node, WithIncludeColumns("Node"), WithIncludeColumns("Name")}, expectedColumns: []string{"Name", "Node"}, expectedCells: []interface{}{"foo", "node1"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, tc.options...).ConvertToTable(context.TODO(), pod, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var columns []string
+			for _, def := range table.ColumnDefinitions {
+				columns = append(columns, def.Name)
+			}
+			if !reflect.DeepEqual(tc.expectedColumns, columns) {
+				t.Errorf("expected columns %v, got %v", tc.expectedColumns, columns)
+			}
+			if !reflect.DeepEqual(tc.expectedCells, table.Rows[0].Cells) {
+				t.Errorf("expected cells %v, got %v", tc.expectedCells, table.Rows[0].Cells)
+			}
+		})
+	}
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithIncludeColumns("Name", "Status"))
+	if _, err := c.ConvertToTable(context.TODO(), pod, nil); err == nil || !strings.Contains(err.Error(), `unknown columns ["Status"]`) {
+		t.Errorf("expected an error for the unknown column, got %v", err)
+	}
+
+	// the first error of the columns is kept
+	c = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithColumnPlacement(nil, []PrinterColumn{{Name: "Bad", JSONPath: ".spec[[bad"}}), WithIncludeColumns("Status"))
+	if _, err := c.ConvertToTable(context.TODO(), pod, nil); err == nil || !strings.Contains(err.Error(), "unrecognized column definition") {
+		t.Errorf("expected the error of the malformed column, got %v", err)
+	}
+}
+
+func TestDefaultTableConvertorRowCellsAreIndependent(t *testing.T) {
+	list := &examplev1.PodList{}
+	for i := 0; i < 10; i++ {
+		list.Items = append(list.Items, examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i)}})
+	}
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range table.Rows {
+		table.Rows[i].Cells = append(table.Rows[i].Cells, "extra")
+	}
+	for i, row := range table.Rows {
+		if expected := []interface{}{fmt.Sprintf("pod-%d", i), "", "extra"}; !reflect.DeepEqual(expected, row.Cells) {
+			t.Errorf("row %d: expected cells %#v, got %#v", i, expected, row.Cells)
+		}
+	}
+}
+
+func TestDefaultTableConvertorPreallocatesRows(t *testing.T) {
+	list := &examplev1.PodList{}
+	for i := 0; i < 100; i++ {
+		list.Items = append(list.Items, examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i)}})
+	}
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 100 || cap(table.Rows) != 100 {
+		t.Errorf("expected 100 preallocated rows, got %d rows of capacity %d", len(table.Rows), cap(table.Rows))
+	}
+	table, err = NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, WithMaxRows(10)).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 10 || cap(table.Rows) != 10 {
+		t.Errorf("expected 10 preallocated rows, got %d rows of capacity %d", len(table.Rows), cap(table.Rows))
+	}
+}
+
+func TestDefaultTableConvertorUnstructuredList(t *testing.T) {
+	// an unstructured list holds its items in its content, without an Items field
+	list := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "WidgetList",
+		"metadata":   map[string]interface{}{"resourceVersion": "7"},
+		"items": []interface{}{
+			newUnstructured("a", nil).Object,
+			newUnstructured("b", nil).Object,
+			newUnstructured("c", nil).Object,
+		},
+	}}
+	table, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "widgets"}).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 3 || cap(table.Rows) != 3 || table.Rows[2].Cells[0] != "c" {
+		t.Errorf("expected 3 preallocated rows, got %d rows of capacity %d", len(table.Rows), cap(table.Rows))
+	}
+
+	truncated, err := NewDefaultTableConvertor(schema.GroupResource{Resource: "widgets"}, WithMaxRows(1)).ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(truncated.Rows) != 1 || truncated.RemainingItemCount == nil || *truncated.RemainingItemCount != 2 {
+		t.Errorf("expected 1 row and 2 remaining items, got %d rows and %v", len(truncated.Rows), truncated.RemainingItemCount)
+	}
+}
+
+func BenchmarkDefaultTableConvertorLargeList(b *testing.B) {
+	list := &example.PodList{Items: make([]example.Pod, 10000)}
+	for i := range list.Items {
+		list.Items[i].ObjectMeta = metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), CreationTimestamp: metav1.NewTime(testNow)}
+	}
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+	ctx := context.TODO()
+
+	for _, policy := range []metav1.IncludeObjectPolicy{metav1.IncludeNone, metav1.IncludeMetadata, metav1.IncludeObject} {
+		options := &metav1.TableOptions{IncludeObject: policy}
+		b.Run(string(policy), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.ConvertToTable(ctx, list, options); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
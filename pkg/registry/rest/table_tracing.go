@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// startConversionSpan starts a span with the given name for a conversion by c in ctx, as a child
+// of the span of ctx, which the tracing filter starts for sampled requests. Spans are only started
+// below recording spans: when tracing is disabled or the request is not sampled, ctx is returned
+// as is with a nil span, so that untraced conversions do not allocate.
+func (c *defaultTableConvertor) startConversionSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return ctx, nil
+	}
+	return parent.Tracer().Start(ctx, name, trace.WithAttributes(
+		attribute.String("resource", requestResource(ctx, c.defaultQualifiedResource).String()),
+	))
+}
+
+// endConversionSpan ends span, if it is not nil, with the number of rows of table or the error of
+// the conversion.
+func endConversionSpan(span trace.Span, table *metav1.Table, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(attribute.Int("rows", len(table.Rows)))
+	}
+	span.End()
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestConversionSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	list := &metav1.List{Items: []runtime.RawExtension{{Object: newUnstructured("foo", nil)}, {Object: newUnstructured("bar", nil)}}}
+	c := NewDefaultTableConvertor(schema.GroupResource{Group: "example.com", Resource: "widgets"})
+
+	ctx, request := tp.Tracer("test").Start(context.TODO(), "request")
+	if _, err := c.ConvertToTable(ctx, list, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ConvertToTableChunk(ctx, c, list, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ConvertToTable(ctx, &metav1.Status{}, nil); err == nil {
+		t.Fatal("expected an error for an object without metadata")
+	}
+	request.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 4 {
+		t.Fatalf("expected 4 spans, got %d", len(spans))
+	}
+	resource := attribute.String("resource", "widgets.example.com")
+	for i, expected := range []struct {
+		name       string
+		attributes []attribute.KeyValue
+		status     codes.Code
+	}{
+		{name: "ConvertToTable", attributes: []attribute.KeyValue{resource, attribute.Int("rows", 2)}},
+		{name: "ConvertToTableChunk", attributes: []attribute.KeyValue{resource, attribute.Int("rows", 2)}},
+		{name: "ConvertToTable", attributes: []attribute.KeyValue{resource}, status: codes.Error},
+	} {
+		span := spans[i]
+		if span.Name != expected.name || span.StatusCode != expected.status {
+			t.Errorf("span %d: expected %s with status %v, got %s with status %v", i, expected.name, expected.status, span.Name, span.StatusCode)
+		}
+		if !reflect.DeepEqual(expected.attributes, span.Attributes) {
+			t.Errorf("span %d: expected attributes %v, got %v", i, expected.attributes, span.Attributes)
+		}
+		if span.Parent.SpanID() != request.SpanContext().SpanID() {
+			t.Errorf("span %d: expected the request span as parent", i)
+		}
+	}
+
+	exporter.Reset()
+	if _, err := c.ConvertToTable(context.TODO(), list, nil); err != nil {
+		t.Fatal(err)
+	}
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Errorf("expected no span without a traced request, got %d", len(spans))
+	}
+}
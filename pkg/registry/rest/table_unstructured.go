@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/clock"
+)
+
+// NewUnstructuredTableConvertor creates a convertor for unstructured objects, such as the custom
+// resources served by a generic apiserver, rendering one column per printer column. Unlike
+// NewJSONPathTableConvertor, the JSONPath of every column must be a plain field path such as
+// ".status.replicas", which is read without copying the object; an error is returned otherwise,
+// or if any of the types is not valid according to ValidColumnType. Fields that are missing from
+// an object render an empty (nil) cell, and objects, arrays and other non-scalar values are
+// rendered as their JSON encoding. Objects that are not unstructured are not acceptable, and the
+// errors name the resource of the request, or defaultQualifiedResource for conversions outside of
+// a request.
+func NewUnstructuredTableConvertor(defaultQualifiedResource schema.GroupResource, columns []PrinterColumn) (TableConvertor, error) {
+	c := &defaultTableConvertor{defaultQualifiedResource: defaultQualifiedResource, clock: clock.RealClock{}}
+	var tableColumns []column
+	for _, col := range columns {
+		if err := validateColumnType(col.definition()); err != nil {
+			return nil, err
+		}
+		tableColumn, err := unstructuredColumn(col, c.defaultQualifiedResource)
+		if err != nil {
+			return nil, err
+		}
+		tableColumns = append(tableColumns, tableColumn)
+	}
+	c.setColumns(tableColumns)
+	return c, nil
+}
+
+// unstructuredColumn returns a column reading its cells from the field at the path of col. Objects
+// that are not unstructured are not acceptable for resource, or the resource of the request.
+func unstructuredColumn(col PrinterColumn, resource schema.GroupResource) (column, error) {
+	fields, err := fieldPath(col.JSONPath)
+	if err != nil {
+		return column{}, fmt.Errorf("unrecognized column definition %q: %v", col.JSONPath, err)
+	}
+	return column{
+		definition: col.definition(),
+		jsonPath:   col.JSONPath,
+		cell: func(ctx context.Context, obj runtime.Object, _ metav1.Object) (interface{}, error) {
+			u, ok := obj.(runtime.Unstructured)
+			if !ok {
+				return nil, newNotAcceptableError(ctx, resource)
+			}
+			value, found, err := unstructured.NestedFieldNoCopy(u.UnstructuredContent(), fields...)
+			if err != nil || !found {
+				return nil, nil
+			}
+			return unstructuredCell(col, value)
+		},
+	}, nil
+}
+
+// fieldPath splits a JSONPath that only selects nested fields, like ".spec.replicas", into the
+// names of the fields.
+func fieldPath(path string) ([]string, error) {
+	fields := strings.Split(strings.TrimPrefix(path, "."), ".")
+	for _, field := range fields {
+		if len(field) == 0 || strings.ContainsAny(field, "[]{}()*?@$,:'\"\\ ") {
+			return nil, fmt.Errorf("only field paths such as .spec.replicas are supported")
+		}
+	}
+	return fields, nil
+}
+
+// unstructuredCell returns the cell of col for an unstructured value.
+func unstructuredCell(col PrinterColumn, value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	return col.numberFormat().FormatCell(col.Type, col.Format, value), nil
+}
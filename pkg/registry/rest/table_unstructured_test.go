@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/apis/example"
+)
+
+func TestUnstructuredTableConvertor(t *testing.T) {
+	columns := []PrinterColumn{
+		{Name: "Name", Type: "string", Format: "name", JSONPath: ".metadata.name"},
+		{Name: "Replicas", Type: "integer", JSONPath: ".spec.replicas"},
+		{Name: "Ready", Type: "boolean", JSONPath: "status.ready"},
+		{Name: "Selector", Type: "string", JSONPath: ".spec.selector"},
+	}
+	c, err := NewUnstructuredTableConvertor(schema.GroupResource{Group: "example.com", Resource: "widgets"}, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+		*newUnstructured("full", map[string]interface{}{
+			"spec":   map[string]interface{}{"replicas": float64(3), "selector": map[string]interface{}{"app": "foo"}},
+			"status": map[string]interface{}{"ready": true},
+		}),
+		*newUnstructured("empty", nil),
+		*newUnstructured("malformed", map[string]interface{}{"spec": "replicas", "status": []interface{}{"ready"}}),
+	}}
+
+	table, err := c.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != len(columns) {
+		t.Errorf("expected %d columns, got %#v", len(columns), table.ColumnDefinitions)
+	}
+	expected := [][]interface{}{
+		{"full", int64(3), true, `{"app":"foo"}`},
+		{"empty", nil, nil, nil},
+		{"malformed", nil, nil, nil},
+	}
+	for i, row := range table.Rows {
+		if !reflect.DeepEqual(expected[i], row.Cells) {
+			t.Errorf("%d: expected cells %#v, got %#v", i, expected[i], row.Cells)
+		}
+	}
+
+	pod := &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	if _, err := c.ConvertToTable(context.TODO(), pod, nil); err == nil {
+		t.Errorf("expected typed objects not to be acceptable")
+	} else if resource, ok := IsNotAcceptable(err); !ok || resource.String() != "widgets.example.com" {
+		t.Errorf("expected a not acceptable error for widgets.example.com, got %v", err)
+	}
+	ctx := WithTableResource(context.TODO(), schema.GroupResource{Group: "example.com", Resource: "gadgets"})
+	if _, err := c.ConvertToTable(ctx, pod, nil); err == nil {
+		t.Errorf("expected typed objects not to be acceptable")
+	} else if resource, ok := IsNotAcceptable(err); !ok || resource.String() != "gadgets.example.com" {
+		t.Errorf("expected a not acceptable error for gadgets.example.com, got %v", err)
+	}
+}
+
+func TestUnstructuredTableConvertorInvalidPath(t *testing.T) {
+	for _, path := range []string{"", ".", ".spec..replicas", ".spec.containers[0].name", "{.spec.replicas}", ".items[*]"} {
+		if _, err := NewUnstructuredTableConvertor(schema.GroupResource{Resource: "widgets"}, []PrinterColumn{{Name: "Column", Type: "string", JSONPath: path}}); err == nil {
+			t.Errorf("%q: expected an error", path)
+		}
+	}
+	if _, err := NewUnstructuredTableConvertor(schema.GroupResource{Resource: "widgets"}, []PrinterColumn{{Name: "Replicas", Type: "int", JSONPath: ".spec.replicas"}}); err == nil {
+		t.Errorf("expected an error for an unknown column type")
+	}
+}
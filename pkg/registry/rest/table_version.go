@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// WithTableVersion returns a copy of parent in which table convertors set the apiVersion of the
+// tables they return to gv, the group version of the Table negotiated for the request, which is
+// either meta.k8s.io/v1 or meta.k8s.io/v1beta1. Other group versions are ignored. Without it,
+// tables are returned with the version selected by TableVersion, or meta.k8s.io/v1; in every case
+// their kind is "Table".
+func WithTableVersion(parent context.Context, gv schema.GroupVersion) context.Context {
+	if !isTableVersion(gv) {
+		return parent
+	}
+	return context.WithValue(parent, tableVersionKey, gv)
+}
+
+// TableVersion selects the group version of the tables returned for requests without a version
+// set with WithTableVersion, meta.k8s.io/v1 or meta.k8s.io/v1beta1 for clients that only decode
+// v1beta1 tables. Other group versions are ignored. The metadata embedded in rows with the
+// Metadata includeObject policy has the apiVersion of the table, so that clients decode
+// PartialObjectMetadata from the group version they requested.
+func TableVersion(gv schema.GroupVersion) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		if isTableVersion(gv) {
+			c.tableVersion = gv
+		}
+	}
+}
+
+// isTableVersion reports whether gv is a group version of the Table kind.
+func isTableVersion(gv schema.GroupVersion) bool {
+	return gv == metav1.SchemeGroupVersion || gv == metav1beta1.SchemeGroupVersion
+}
+
+// tableVersionFor returns the group version of the tables converted for the request in ctx.
+func (c *defaultTableConvertor) tableVersionFor(ctx context.Context) schema.GroupVersion {
+	if gv, ok := ctx.Value(tableVersionKey).(schema.GroupVersion); ok {
+		return gv
+	}
+	if !c.tableVersion.Empty() {
+		return c.tableVersion
+	}
+	return metav1.SchemeGroupVersion
+}
+
+// tableTypeMeta returns the type metadata of the tables of the group version gv.
+func tableTypeMeta(gv schema.GroupVersion) metav1.TypeMeta {
+	return metav1.TypeMeta{Kind: "Table", APIVersion: gv.String()}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	examplev1 "k8s.io/apiserver/pkg/apis/example/v1"
+)
+
+func TestTableTypeMeta(t *testing.T) {
+	testCases := []struct {
+		name       string
+		ctx        context.Context
+		object     runtime.Object
+		opts       []TableConvertorOption
+		apiVersion string
+	}{
+		{name: "default", ctx: context.TODO(), object: &examplev1.Pod{}, apiVersion: "meta.k8s.io/v1"},
+		{name: "empty list", ctx: context.TODO(), object: &examplev1.PodList{}, apiVersion: "meta.k8s.io/v1"},
+		{name: "v1", ctx: WithTableVersion(context.TODO(), metav1.SchemeGroupVersion), object: &examplev1.Pod{}, apiVersion: "meta.k8s.io/v1"},
+		{name: "v1beta1", ctx: WithTableVersion(context.TODO(), metav1beta1.SchemeGroupVersion), object: &examplev1.Pod{}, apiVersion: "meta.k8s.io/v1beta1"},
+		{name: "unknown version", ctx: WithTableVersion(context.TODO(), schema.GroupVersion{Group: "example.com", Version: "v1"}), object: &examplev1.Pod{}, apiVersion: "meta.k8s.io/v1"},
+		{name: "status", ctx: WithTableVersion(context.TODO(), metav1beta1.SchemeGroupVersion), object: &metav1.Status{}, opts: []TableConvertorOption{WithStatusRows()}, apiVersion: "meta.k8s.io/v1beta1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, tc.opts...)
+			table, err := c.ConvertToTable(tc.ctx, tc.object, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := (metav1.TypeMeta{Kind: "Table", APIVersion: tc.apiVersion}); table.TypeMeta != expected {
+				t.Errorf("expected type metadata %#v, got %#v", expected, table.TypeMeta)
+			}
+		})
+	}
+}
+
+func TestTableVersion(t *testing.T) {
+	pod := &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Spec: examplev1.PodSpec{NodeName: "node1"}}
+	testCases := []struct {
+		name    string
+		ctx     context.Context
+		opts    []TableConvertorOption
+		options *metav1.TableOptions
+		version schema.GroupVersion
+	}{
+		{name: "default", ctx: context.TODO(), version: metav1.SchemeGroupVersion},
+		{name: "v1beta1 option", ctx: context.TODO(), opts: []TableConvertorOption{TableVersion(metav1beta1.SchemeGroupVersion)}, version: metav1beta1.SchemeGroupVersion},
+		{name: "unknown option", ctx: context.TODO(), opts: []TableConvertorOption{TableVersion(schema.GroupVersion{Group: "meta.k8s.io", Version: "v2"})}, version: metav1.SchemeGroupVersion},
+		{name: "v1 request", ctx: WithTableVersion(context.TODO(), metav1.SchemeGroupVersion), opts: []TableConvertorOption{TableVersion(metav1beta1.SchemeGroupVersion)}, version: metav1.SchemeGroupVersion},
+		{name: "v1beta1 request", ctx: WithTableVersion(context.TODO(), metav1beta1.SchemeGroupVersion), version: metav1beta1.SchemeGroupVersion},
+		{
+			name:    "v1beta1 limited object",
+			ctx:     WithTableVersion(context.TODO(), metav1beta1.SchemeGroupVersion),
+			opts:    []TableConvertorOption{WithMaxEmbeddedObjectBytes(1)},
+			options: &metav1.TableOptions{IncludeObject: metav1.IncludeObject},
+			version: metav1beta1.SchemeGroupVersion,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, tc.opts...)
+			var options runtime.Object
+			if tc.options != nil {
+				options = tc.options
+			}
+			table, err := c.ConvertToTable(tc.ctx, pod, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if table.APIVersion != tc.version.String() || table.Kind != "Table" {
+				t.Errorf("expected a %s table, got %#v", tc.version, table.TypeMeta)
+			}
+			partial, ok := table.Rows[0].Object.Object.(*metav1.PartialObjectMetadata)
+			if !ok {
+				t.Fatalf("expected embedded metadata, got %T", table.Rows[0].Object.Object)
+			}
+			if expected := tc.version.WithKind("PartialObjectMetadata"); partial.GroupVersionKind() != expected {
+				t.Errorf("expected embedded metadata of kind %s, got %s", expected, partial.GroupVersionKind())
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// WithVersionedColumns renders different columns depending on the API group and version of the
+// request, for stores serving several versions whose tables differ. Requests for a group version
+// found in columns render all its printer columns, which replace all the columns of the convertor,
+// including the default and extra columns and the selection of WithIncludeColumns, while the other
+// options still apply. Requests for other versions, and conversions without request info in their
+// context, fall back to the columns of the convertor. Conversions for a version fail if any of its
+// JSONPath expressions is malformed or any of its types is not valid according to ValidColumnType.
+func WithVersionedColumns(columns map[schema.GroupVersion][]PrinterColumn) TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		if c.versionedPrinterColumns == nil {
+			c.versionedPrinterColumns = map[schema.GroupVersion][]PrinterColumn{}
+		}
+		for gv, printerColumns := range columns {
+			c.versionedPrinterColumns[gv] = printerColumns
+		}
+	}
+}
+
+// setVersionedColumns precomputes a convertor for every group version with its own columns, once
+// all the options have been applied to c.
+func (c *defaultTableConvertor) setVersionedColumns() {
+	if len(c.versionedPrinterColumns) == 0 {
+		return
+	}
+	c.versioned = make(map[schema.GroupVersion]*defaultTableConvertor, len(c.versionedPrinterColumns))
+	for gv, printerColumns := range c.versionedPrinterColumns {
+		versioned := c.withColumnsOnly()
+		var columns []column
+		for _, col := range printerColumns {
+			tableColumn, err := printerColumn(col)
+			if err != nil {
+				versioned.failColumns(fmt.Errorf("invalid printer columns for %s: %v", gv, err))
+				break
+			}
+			columns = append(columns, tableColumn)
+		}
+		versioned.setColumns(columns)
+		c.versioned[gv] = &versioned
+	}
+}
+
+// withColumnsOnly returns a copy of c without the options adding, placing or selecting columns, and
+// without versioned columns, for conversions whose columns replace all the columns of c while the
+// other options still apply.
+func (c *defaultTableConvertor) withColumnsOnly() defaultTableConvertor {
+	copied := *c
+	copied.versionedPrinterColumns = nil
+	copied.versioned = nil
+	copied.extraColumns = nil
+	copied.columnsBefore = nil
+	copied.columnsAfter = nil
+	copied.kindColumn = false
+	copied.includeColumns = nil
+	copied.typeCell = nil
+	copied.warningTypes = nil
+	copied.defaultColumnsOnly = false
+	return copied
+}
+
+// forRequest returns the convertor rendering the columns of the group version of the request in
+// ctx, which is c unless the version has its own columns.
+func (c *defaultTableConvertor) forRequest(ctx context.Context) *defaultTableConvertor {
+	if len(c.versioned) == 0 {
+		return c
+	}
+	info, ok := genericapirequest.RequestInfoFrom(ctx)
+	if !ok {
+		return c
+	}
+	if versioned, ok := c.versioned[schema.GroupVersion{Group: info.APIGroup, Version: info.APIVersion}]; ok {
+		return versioned
+	}
+	return c
+}
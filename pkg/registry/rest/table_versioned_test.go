@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	examplev1 "k8s.io/apiserver/pkg/apis/example/v1"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestWithVersionedColumns(t *testing.T) {
+	pod := &examplev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", CreationTimestamp: metav1.NewTime(testNow)},
+		Spec:       examplev1.PodSpec{NodeName: "node1", Hostname: "host1"},
+	}
+	v1 := schema.GroupVersion{Group: "example.com", Version: "v1"}
+	v1beta1 := schema.GroupVersion{Group: "example.com", Version: "v1beta1"}
+	c := NewDefaultTableConvertor(schema.GroupResource{Group: "example.com", Resource: "pods"}, WithVersionedColumns(map[schema.GroupVersion][]PrinterColumn{
+		v1:      {{Name: "Name", Type: "string", Format: "name", JSONPath: ".metadata.name"}, {Name: "Node", Type: "string", JSONPath: ".spec.nodeName"}},
+		v1beta1: {{Name: "Name", Type: "string", Format: "name", JSONPath: ".metadata.name"}, {Name: "Host", Type: "string", JSONPath: ".spec.hostname"}},
+	}), WithRowConditions(func(runtime.Object) []metav1.TableRowCondition {
+		return []metav1.TableRowCondition{{Type: metav1.RowCompleted, Status: metav1.ConditionTrue}}
+	}))
+	requestFor := func(version string) context.Context {
+		return genericapirequest.WithRequestInfo(context.TODO(), &genericapirequest.RequestInfo{APIGroup: "example.com", APIVersion: version, Resource: "pods"})
+	}
+
+	testCases := []struct {
+		name            string
+		ctx             context.Context
+		expectedColumns []string
+		expectedCells   []interface{}
+	}{
+		{name: "v1", ctx: requestFor("v1"), expectedColumns: []string{"Name", "Node"}, expectedCells: []interface{}{"foo", "node1"}},
+		{name: "v1beta1", ctx: requestFor("v1beta1"), expectedColumns: []string{"Name", "Host"}, expectedCells: []interface{}{"foo", "host1"}},
+		{name: "unknown version", ctx: requestFor("v2"), expectedColumns: []string{"Name", "Created At"}, expectedCells: []interface{}{"foo", "2021-06-01T12:00:00Z"}},
+		{name: "no request info", ctx: context.TODO(), expectedColumns: []string{"Name", "Created At"}, expectedCells: []interface{}{"foo", "2021-06-01T12:00:00Z"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			table, err := c.ConvertToTable(tc.ctx, pod, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var columns []string
+			for _, def := range table.ColumnDefinitions {
+				columns = append(columns, def.Name)
+			}
+			if !reflect.DeepEqual(tc.expectedColumns, columns) {
+				t.Errorf("expected columns %v, got %v", tc.expectedColumns, columns)
+			}
+			if !reflect.DeepEqual(tc.expectedCells, table.Rows[0].Cells) {
+				t.Errorf("expected cells %v, got %v", tc.expectedCells, table.Rows[0].Cells)
+			}
+			if len(table.Rows[0].Conditions) != 1 {
+				t.Errorf("expected the other options to apply, got conditions %v", table.Rows[0].Conditions)
+			}
+		})
+	}
+
+	var streamed []interface{}
+	if _, err := c.(TableStreamConvertor).ConvertToTableStream(requestFor("v1beta1"), pod, nil, func(row metav1.TableRow) error {
+		streamed = row.Cells
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []interface{}{"foo", "host1"}; !reflect.DeepEqual(expected, streamed) {
+		t.Errorf("expected streamed cells %v, got %v", expected, streamed)
+	}
+
+	malformed := NewDefaultTableConvertor(schema.GroupResource{Group: "example.com", Resource: "pods"}, WithVersionedColumns(map[schema.GroupVersion][]PrinterColumn{
+		v1: {{Name: "Bad", Type: "string", JSONPath: ".spec[*"}},
+	}))
+	if _, err := malformed.ConvertToTable(requestFor("v1"), pod, nil); err == nil || !strings.Contains(err.Error(), "example.com/v1") {
+		t.Errorf("expected an error naming the version, got %v", err)
+	}
+	if _, err := malformed.ConvertToTable(requestFor("v1beta1"), pod, nil); err != nil {
+		t.Errorf("unexpected error for a version without columns: %v", err)
+	}
+	invalidType := NewDefaultTableConvertor(schema.GroupResource{Group: "example.com", Resource: "pods"}, WithVersionedColumns(map[schema.GroupVersion][]PrinterColumn{
+		v1: {{Name: "Node", Type: "text", JSONPath: ".spec.nodeName"}},
+	}))
+	if _, err := invalidType.ConvertToTable(requestFor("v1"), pod, nil); err == nil || !strings.Contains(err.Error(), `unknown type "text"`) {
+		t.Errorf("expected an error for the unknown type, got %v", err)
+	}
+}
+
+func TestWithVersionedColumnsAndIncludeColumns(t *testing.T) {
+	pod := &examplev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", CreationTimestamp: metav1.NewTime(testNow)},
+		Spec:       examplev1.PodSpec{NodeName: "node1"},
+	}
+	v1 := schema.GroupVersion{Group: "example.com", Version: "v1"}
+	c := NewDefaultTableConvertor(schema.GroupResource{Group: "example.com", Resource: "pods"}, WithIncludeColumns("Name"), WithVersionedColumns(map[schema.GroupVersion][]PrinterColumn{
+		v1: {{Name: "Name", Type: "string", Format: "name", JSONPath: ".metadata.name"}, {Name: "Node", Type: "string", JSONPath: ".spec.nodeName"}},
+	}))
+	for version, expected := range map[string][]interface{}{"v1": {"foo", "node1"}, "v2": {"foo"}} {
+		ctx := genericapirequest.WithRequestInfo(context.TODO(), &genericapirequest.RequestInfo{APIGroup: "example.com", APIVersion: version, Resource: "pods"})
+		table, err := c.ConvertToTable(ctx, pod, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", version, err)
+		}
+		if !reflect.DeepEqual(expected, table.Rows[0].Cells) {
+			t.Errorf("%s: expected cells %v, got %v", version, expected, table.Rows[0].Cells)
+		}
+	}
+}
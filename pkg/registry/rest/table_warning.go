@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/warning"
+)
+
+// WithDefaultColumnsWarning adds a warning to the responses of table conversions that only render
+// the default columns, because the resource defines no printer columns. It helps operators find
+// resources that should define printer columns. The warning is added through the warning recorder
+// of the request context, for instance as a Warning response header. Conversions rendering any
+// other column, such as extra, placed or versioned columns or the kind column, add no warning.
+func WithDefaultColumnsWarning() TableConvertorOption {
+	return func(c *defaultTableConvertor) {
+		c.defaultColumnsWarning = true
+	}
+}
+
+// onlyDefaultColumns reports whether every column of c is one of the default columns, and no
+// version of the resource has its own columns.
+func (c *defaultTableConvertor) onlyDefaultColumns(defaults []column) bool {
+	if len(c.versioned) > 0 {
+		return false
+	}
+	names := sets.NewString()
+	for _, col := range defaults {
+		names.Insert(col.definition.Name)
+	}
+	for _, col := range c.columns {
+		if !names.Has(col.definition.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// warnDefaultColumns adds the warning for a conversion of the resource of the request in ctx with
+// only the default columns.
+func warnDefaultColumns(ctx context.Context, defaultQualifiedResource schema.GroupResource) {
+	resource := requestResource(ctx, defaultQualifiedResource)
+	warning.AddWarning(ctx, "", fmt.Sprintf("%s defines no printer columns, only the default columns are shown", resource))
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	examplev1 "k8s.io/apiserver/pkg/apis/example/v1"
+	"k8s.io/apiserver/pkg/warning"
+)
+
+type fakeWarningRecorder struct {
+	warnings []string
+}
+
+func (r *fakeWarningRecorder) AddWarning(_, text string) {
+	r.warnings = append(r.warnings, text)
+}
+
+func TestDefaultColumnsWarning(t *testing.T) {
+	pod := &examplev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Spec: examplev1.PodSpec{NodeName: "node1"}}
+	defaults := NewDefaultTableConvertor(schema.GroupResource{Group: "example.com", Resource: "pods"}, WithAgeColumn())
+
+	convert := func(c TableConvertor) []string {
+		recorder := &fakeWarningRecorder{}
+		if _, err := c.ConvertToTable(warning.WithWarningRecorder(context.TODO(), recorder), pod, nil); err != nil {
+			t.Fatal(err)
+		}
+		return recorder.warnings
+	}
+
+	if warnings := convert(defaults); len(warnings) > 0 {
+		t.Errorf("expected no warning by default, got %v", warnings)
+	}
+
+	warned := NewDefaultTableConvertor(schema.GroupResource{Group: "example.com", Resource: "pods"}, WithAgeColumn(), WithNamespaceColumn(), WithDefaultColumnsWarning())
+	if expected, warnings := []string{"pods.example.com defines no printer columns, only the default columns are shown"}, convert(warned); !reflect.DeepEqual(expected, warnings) {
+		t.Errorf("expected warnings %v, got %v", expected, warnings)
+	}
+	for name, opt := range map[string]TableConvertorOption{
+		"extra columns": WithExtraColumns(ExtraColumn{
+			Definition: metav1.TableColumnDefinition{Name: "Node", Type: "string"},
+			Extract:    func(obj runtime.Object) (interface{}, error) { return obj.(*examplev1.Pod).Spec.NodeName, nil },
+		}),
+		"placed columns": WithColumnPlacement(nil, []PrinterColumn{{Name: "Node", Type: "string", JSONPath: ".spec.nodeName"}}),
+		"versioned columns": WithVersionedColumns(map[schema.GroupVersion][]PrinterColumn{
+			{Group: "example.com", Version: "v1"}: {{Name: "Node", Type: "string", JSONPath: ".spec.nodeName"}},
+		}),
+		"kind column": WithKindColumn(),
+	} {
+		custom := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}, opt, WithDefaultColumnsWarning())
+		if warnings := convert(custom); len(warnings) > 0 {
+			t.Errorf("%s: expected no warning, got %v", name, warnings)
+		}
+	}
+}
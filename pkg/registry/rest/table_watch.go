@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// WatchToTable converts the objects of the Added, Modified and Deleted events read from events
+// with c, and sends one event per input event on the returned channel, with the same type and a
+// *metav1.Table of one row as object. Only the first table carries column definitions, like the
+// tables of a watch served in the Table format. Deleted events produce the row of the last state
+// of the deleted object, so that clients can remove it from their output.
+//
+// Error and Bookmark events are sent unchanged. An object that cannot be converted is reported by
+// an Error event carrying the status of the conversion error, and the watch goes on. The returned
+// channel is closed once events is closed or ctx is done; callers that stop reading it must
+// cancel ctx.
+func WatchToTable(ctx context.Context, events <-chan watch.Event, c TableConvertor) <-chan watch.Event {
+	out := make(chan watch.Event)
+	go func() {
+		defer close(out)
+		isFirst := true
+		for {
+			var event watch.Event
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok = <-events:
+				if !ok {
+					return
+				}
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified, watch.Deleted:
+				table, err := ConvertToTableChunk(ctx, c, event.Object, nil, isFirst)
+				if err != nil {
+					event = watch.Event{Type: watch.Error, Object: errorStatus(err)}
+					break
+				}
+				isFirst = false
+				event.Object = table
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- event:
+			}
+		}
+	}()
+	return out
+}
+
+// errorStatus returns the status describing err.
+func errorStatus(err error) *metav1.Status {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		s := status.Status()
+		return &s
+	}
+	return &apierrors.NewInternalError(err).ErrStatus
+}
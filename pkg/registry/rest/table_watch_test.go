@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/apis/example"
+)
+
+func TestWatchToTable(t *testing.T) {
+	pod := func(name string) *example.Pod {
+		return &example.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	bookmark := pod("")
+	bookmark.ResourceVersion = "12"
+	failure := &metav1.Status{Status: metav1.StatusFailure, Reason: metav1.StatusReasonExpired}
+
+	events := make(chan watch.Event, 6)
+	events <- watch.Event{Type: watch.Added, Object: pod("a")}
+	events <- watch.Event{Type: watch.Modified, Object: &metav1.Status{}}
+	events <- watch.Event{Type: watch.Modified, Object: pod("a")}
+	events <- watch.Event{Type: watch.Bookmark, Object: bookmark}
+	events <- watch.Event{Type: watch.Deleted, Object: pod("a")}
+	events <- watch.Event{Type: watch.Error, Object: failure}
+	close(events)
+
+	c := NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"})
+	var got []watch.Event
+	for event := range WatchToTable(context.TODO(), events, c) {
+		got = append(got, event)
+	}
+	expectedTypes := []watch.EventType{watch.Added, watch.Error, watch.Modified, watch.Bookmark, watch.Deleted, watch.Error}
+	if len(got) != len(expectedTypes) {
+		t.Fatalf("expected %d events, got %#v", len(expectedTypes), got)
+	}
+	for i, event := range got {
+		if event.Type != expectedTypes[i] {
+			t.Errorf("event %d: expected type %s, got %s", i, expectedTypes[i], event.Type)
+		}
+	}
+	for i, headers := range map[int]bool{0: true, 2: false, 4: false} {
+		table, ok := got[i].Object.(*metav1.Table)
+		if !ok {
+			t.Fatalf("event %d: expected a table, got %T", i, got[i].Object)
+		}
+		if len(table.Rows) != 1 || table.Rows[0].Cells[0] != "a" {
+			t.Errorf("event %d: expected the row of the pod, got %#v", i, table.Rows)
+		}
+		if (len(table.ColumnDefinitions) > 0) != headers {
+			t.Errorf("event %d: expected headers %v, got %#v", i, headers, table.ColumnDefinitions)
+		}
+	}
+	if _, ok := got[4].Object.(*metav1.Table).Rows[0].Object.Object.(*metav1.PartialObjectMetadata); !ok {
+		t.Errorf("expected the deleted row to embed the metadata of the pod, got %#v", got[4].Object.(*metav1.Table).Rows[0].Object)
+	}
+	if status, ok := got[1].Object.(*metav1.Status); !ok || status.Reason != metav1.StatusReasonNotAcceptable {
+		t.Errorf("expected a not acceptable status for the conversion error, got %#v", got[1].Object)
+	}
+	if got[3].Object != bookmark || got[5].Object != failure {
+		t.Errorf("expected bookmark and error events to be sent unchanged, got %#v and %#v", got[3].Object, got[5].Object)
+	}
+}
+
+func TestWatchToTableCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	events := make(chan watch.Event)
+	out := WatchToTable(ctx, events, NewDefaultTableConvertor(schema.GroupResource{Resource: "pods"}))
+	cancel()
+	if _, ok := <-out; ok {
+		t.Errorf("expected the channel to be closed once the context is done")
+	}
+}